@@ -0,0 +1,177 @@
+// Command mock-oidc is a throwaway OpenID Connect issuer for exercising
+// /api/v1/auth/oidc locally. It approves every authorization request
+// without a login page; pass ?login_hint=<email> to choose who signs in.
+//
+//	go run ./cmd/mock-oidc
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9000
+//	OIDC_MOCK_CLIENT_ID=pai-finance
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type grant struct {
+	clientID  string
+	redirect  string
+	challenge string
+	nonce     string
+	email     string
+	expiresAt time.Time
+}
+
+func main() {
+	addr := os.Getenv("MOCK_OIDC_ADDR")
+	if addr == "" {
+		addr = ":9000"
+	}
+	issuer := os.Getenv("MOCK_OIDC_ISSUER")
+	if issuer == "" {
+		issuer = "http://localhost" + addr
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+	const kid = "mock"
+
+	var (
+		mu     sync.Mutex
+		grants = map[string]grant{}
+	)
+
+	writeJSON := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	oauthError := func(w http.ResponseWriter, code string) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+	}
+
+	http.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"id_token_signing_alg_values_supported": []string{"EdDSA"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	})
+
+	http.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"kid": kid,
+			"use": "sig",
+			"alg": "EdDSA",
+			"x":   base64.RawURLEncoding.EncodeToString(pub),
+		}}})
+	})
+
+	http.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		redirect, err := url.Parse(q.Get("redirect_uri"))
+		if err != nil || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid authorization request", http.StatusBadRequest)
+			return
+		}
+		email := q.Get("login_hint")
+		if email == "" {
+			email = "mock.user@example.com"
+		}
+
+		code := rand.Text()
+		mu.Lock()
+		grants[code] = grant{
+			clientID:  q.Get("client_id"),
+			redirect:  redirect.String(),
+			challenge: q.Get("code_challenge"),
+			nonce:     q.Get("nonce"),
+			email:     email,
+			expiresAt: time.Now().Add(time.Minute),
+		}
+		mu.Unlock()
+
+		back := redirect.Query()
+		back.Set("code", code)
+		back.Set("state", q.Get("state"))
+		redirect.RawQuery = back.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+
+	http.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+			oauthError(w, "invalid_request")
+			return
+		}
+
+		mu.Lock()
+		g, ok := grants[r.PostForm.Get("code")]
+		delete(grants, r.PostForm.Get("code"))
+		mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		switch {
+		case !ok || time.Now().After(g.expiresAt):
+			oauthError(w, "invalid_grant")
+			return
+		case g.clientID != r.PostForm.Get("client_id") || g.redirect != r.PostForm.Get("redirect_uri"):
+			oauthError(w, "invalid_grant")
+			return
+		case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+			oauthError(w, "invalid_grant")
+			return
+		}
+
+		given, _, _ := strings.Cut(g.email, "@")
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"iss":            issuer,
+			"aud":            g.clientID,
+			"sub":            "mock|" + g.email,
+			"email":          g.email,
+			"email_verified": true,
+			"given_name":     given,
+			"family_name":    "Mock",
+			"nonce":          g.nonce,
+			"iat":            now.Unix(),
+			"exp":            now.Add(5 * time.Minute).Unix(),
+		})
+		token.Header["kid"] = kid
+		idToken, err := token.SignedString(priv)
+		if err != nil {
+			oauthError(w, "server_error")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": rand.Text(),
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     idToken,
+		})
+	})
+
+	log.Printf("mock OIDC issuer %s listening on %s", issuer, addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
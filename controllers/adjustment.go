@@ -0,0 +1,490 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// adjustmentError is an adjustment that doesn't fit the log it would post
+// to, reported as a 409.
+type adjustmentError struct {
+	msg string
+}
+
+func (e *adjustmentError) Error() string { return e.msg }
+
+// canApprove reports whether the caller may approve stock adjustments, by
+// the same rule as the approve route, API key scopes included.
+func canApprove(ctx iris.Context) bool {
+	return hasPermission(ctx, models.PermUsers)
+}
+
+// GetStockAdjustments → GET /adjustments
+// Optional filters: store_id, status, reason, from and to (YYYY-MM-DD).
+func (sc *SalesController) GetStockAdjustments(ctx iris.Context) {
+	filter, ok := adjustmentFilter(ctx)
+	if !ok {
+		return
+	}
+	if v := ctx.URLParam("status"); v != "" {
+		filter["status"] = v
+	}
+	if v := ctx.URLParam("reason"); v != "" {
+		filter["reason"] = v
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := sc.AdjustmentColl.Find(timeoutCtx, filter, options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "created_at", Value: -1}}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch adjustments: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	adjustments := []models.StockAdjustment{}
+	if err := cursor.All(timeoutCtx, &adjustments); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode adjustments: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Adjustments fetched successfully!", adjustments)
+}
+
+// GetStockAdjustmentSummary → GET /adjustments/summary
+// Totals approved adjustments per store and reason, with the same store_id,
+// from and to filters as GetStockAdjustments.
+func (sc *SalesController) GetStockAdjustmentSummary(ctx iris.Context) {
+	filter, ok := adjustmentFilter(ctx)
+	if !ok {
+		return
+	}
+	filter["status"] = models.AdjustmentApproved
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := sc.AdjustmentColl.Aggregate(timeoutCtx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"store_id": "$store_id", "reason": "$reason"},
+			"count":   bson.M{"$sum": 1},
+			"added":   bson.M{"$sum": bson.M{"$max": bson.A{"$quantity", 0}}},
+			"removed": bson.M{"$sum": bson.M{"$max": bson.A{bson.M{"$multiply": bson.A{"$quantity", -1}}, 0}}},
+			"net":     bson.M{"$sum": "$quantity"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"store_id": "$_id.store_id",
+			"reason":   "$_id.reason",
+			"count":    1,
+			"added":    1,
+			"removed":  1,
+			"net":      1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "store_id", Value: 1}, {Key: "reason", Value: 1}}}},
+	})
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to aggregate adjustments: "+err.Error(), nil)
+		return
+	}
+	summary := []models.AdjustmentSummary{}
+	if err := cursor.All(timeoutCtx, &summary); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode adjustments: "+err.Error(), nil)
+		return
+	}
+
+	names, err := storeNames(timeoutCtx, sc.StoreColl, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch stores: "+err.Error(), nil)
+		return
+	}
+	for i := range summary {
+		summary[i].StoreName = names[summary[i].StoreID]
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Adjustment summary fetched successfully!", summary)
+}
+
+// AddStockAdjustment → POST /adjustments
+// Records an adjustment against the store's log for the day, which must
+// exist already. Adjustments posted by owners and admins are approved and
+// applied straight away; anyone else's wait for ApproveStockAdjustment.
+func (sc *SalesController) AddStockAdjustment(ctx iris.Context) {
+	var req models.StockAdjustmentRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	if req.Date.IsZero() {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "date is required", nil)
+		return
+	}
+	if !models.IsValidAdjustmentReason(req.Reason) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "reason must be one of "+strings.Join(models.AdjustmentReasons, ", "), nil)
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Reason == models.ReasonOther && req.Note == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "A note is required when the reason is other", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace := workspaceID(ctx)
+	store, err := findStore(timeoutCtx, sc.StoreColl, workspace, req.StoreID)
+	if errors.Is(err, errUnknownStore) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+		return
+	}
+
+	adjustment := models.StockAdjustment{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspace,
+		StoreID:     store.ID,
+		Date:        utils.BusinessDay(req.Date),
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		Note:        req.Note,
+		Status:      models.AdjustmentPending,
+		CreatedBy:   currentUserID(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if len(req.Lines) > 0 {
+		adjustment.Lines, err = sc.resolveAdjustmentLines(timeoutCtx, workspace, req.Lines)
+		var lineErr *lineError
+		if errors.As(err, &lineErr) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", lineErr.Error(), nil)
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch products: "+err.Error(), nil)
+			return
+		}
+		adjustment.Quantity = 0
+		for _, l := range adjustment.Lines {
+			adjustment.Quantity += l.Quantity
+		}
+	} else if adjustment.Quantity == 0 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "quantity must not be zero", nil)
+		return
+	}
+
+	approve := canApprove(ctx)
+	if approve {
+		now := time.Now().UTC()
+		reviewer := currentUserID(ctx)
+		adjustment.Status = models.AdjustmentApproved
+		adjustment.ReviewedBy, adjustment.ReviewedAt = &reviewer, &now
+	}
+
+	var updates []chainUpdate
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		if approve {
+			logID, err := sc.postAdjustment(sctx, adjustment, 1)
+			if err != nil {
+				return err
+			}
+			adjustment.SalesLogID = &logID
+		} else if _, err := sc.adjustmentLog(sctx, adjustment); err != nil {
+			return err
+		}
+		if _, err := sc.AdjustmentColl.InsertOne(sctx, adjustment); err != nil {
+			return err
+		}
+		if !approve {
+			return nil
+		}
+		var err error
+		updates, err = sc.recomputeChain(sctx, adjustment.WorkspaceID, adjustment.StoreID, adjustment.Date)
+		return err
+	})
+	var adjustmentErr *adjustmentError
+	if errors.As(err, &adjustmentErr) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", adjustmentErr.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to record adjustment: "+err.Error(), nil)
+		return
+	}
+
+	sc.Audit.Record(ctx, models.AuditCreate, sc.AdjustmentColl.Name(), adjustment.ID, nil, adjustment)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+	}
+
+	message := "Adjustment posted successfully!"
+	if !approve {
+		message = "Adjustment recorded; it will be applied once approved"
+	}
+	utils.SendResponse(ctx, http.StatusOK, "success", message, iris.Map{
+		"adjustment": adjustment,
+		"affected":   affectedDays(updates),
+	})
+}
+
+// ApproveStockAdjustment → POST /adjustments/{id}/approve
+// Posts a pending adjustment to its log and recomputes the store's chain.
+func (sc *SalesController) ApproveStockAdjustment(ctx iris.Context) {
+	sc.reviewAdjustment(ctx, models.AdjustmentApproved)
+}
+
+// RejectStockAdjustment → POST /adjustments/{id}/reject
+// Rejected adjustments are kept for the record but never posted.
+func (sc *SalesController) RejectStockAdjustment(ctx iris.Context) {
+	sc.reviewAdjustment(ctx, models.AdjustmentRejected)
+}
+
+func (sc *SalesController) reviewAdjustment(ctx iris.Context, status string) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	var req models.ReviewAdjustmentRequest
+	if err := ctx.ReadJSON(&req); err != nil && ctx.GetContentLength() > 0 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var before, adjustment models.StockAdjustment
+	var updates []chainUpdate
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		err := sc.AdjustmentColl.FindOne(sctx, scoped(ctx, bson.M{"_id": objectID, "status": models.AdjustmentPending})).Decode(&before)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		reviewer := currentUserID(ctx)
+		adjustment = before
+		adjustment.Status = status
+		adjustment.ReviewNote = strings.TrimSpace(req.Note)
+		adjustment.ReviewedBy, adjustment.ReviewedAt = &reviewer, &now
+
+		if status == models.AdjustmentApproved {
+			logID, err := sc.postAdjustment(sctx, adjustment, 1)
+			if err != nil {
+				return err
+			}
+			adjustment.SalesLogID = &logID
+		}
+		if _, err := sc.AdjustmentColl.ReplaceOne(sctx, bson.M{"_id": objectID}, adjustment); err != nil {
+			return err
+		}
+		if status != models.AdjustmentApproved {
+			return nil
+		}
+		updates, err = sc.recomputeChain(sctx, adjustment.WorkspaceID, adjustment.StoreID, adjustment.Date)
+		return err
+	})
+	var adjustmentErr *adjustmentError
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Adjustment not found or already reviewed", nil)
+		return
+	} else if errors.As(err, &adjustmentErr) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", adjustmentErr.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	sc.Audit.Record(ctx, models.AuditUpdate, sc.AdjustmentColl.Name(), objectID, before, adjustment)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Adjustment "+status+"!", iris.Map{
+		"adjustment": adjustment,
+		"affected":   affectedDays(updates),
+	})
+}
+
+// DeleteStockAdjustmentByID → DELETE /adjustments/{id}
+// Approved adjustments are reversed and the chain recomputed; only owners
+// and admins may delete those.
+func (sc *SalesController) DeleteStockAdjustmentByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := scoped(ctx, bson.M{"_id": objectID})
+	if !canApprove(ctx) {
+		filter["status"] = bson.M{"$ne": models.AdjustmentApproved}
+	}
+
+	var deleted models.StockAdjustment
+	var updates []chainUpdate
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		err := sc.AdjustmentColl.FindOneAndDelete(sctx, filter).Decode(&deleted)
+		if err != nil {
+			return err
+		}
+		if deleted.Status != models.AdjustmentApproved {
+			return nil
+		}
+		if _, err := sc.postAdjustment(sctx, deleted, -1); err != nil {
+			return err
+		}
+		updates, err = sc.recomputeChain(sctx, deleted.WorkspaceID, deleted.StoreID, deleted.Date)
+		return err
+	})
+	var adjustmentErr *adjustmentError
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Adjustment not found", nil)
+		return
+	} else if errors.As(err, &adjustmentErr) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", adjustmentErr.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	sc.Audit.Record(ctx, models.AuditDelete, sc.AdjustmentColl.Name(), objectID, deleted, nil)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Adjustment deleted successfully!", iris.Map{
+		"id":       objectID,
+		"affected": affectedDays(updates),
+	})
+}
+
+// adjustmentLog returns the store's log for the adjustment's day, checking
+// the adjustment tracks products exactly when the log does.
+func (sc *SalesController) adjustmentLog(cctx context.Context, a models.StockAdjustment) (models.SalesLog, error) {
+	var log models.SalesLog
+	err := sc.SalesColl.FindOne(cctx, bson.M{
+		"workspace_id": a.WorkspaceID,
+		"store_id":     a.StoreID,
+		"date":         bson.M{"$gte": a.Date, "$lt": a.Date.AddDate(0, 0, 1)},
+	}).Decode(&log)
+	if err == mongo.ErrNoDocuments {
+		return log, &adjustmentError{"The store needs a sales log for " + utils.DayKey(a.Date) + " before stock can be adjusted"}
+	} else if err != nil {
+		return log, err
+	}
+
+	switch {
+	case len(log.Lines) > 0 && len(a.Lines) == 0:
+		return log, &adjustmentError{"The sales log tracks products; adjust lines instead of a quantity"}
+	case len(log.Lines) == 0 && len(a.Lines) > 0:
+		return log, &adjustmentError{"The sales log doesn't track products; adjust a quantity instead of lines"}
+	}
+	return log, nil
+}
+
+// postAdjustment adds sign times the adjustment to its log's Adjustment and
+// returns the log's ID.
+func (sc *SalesController) postAdjustment(sctx mongo.SessionContext, a models.StockAdjustment, sign int) (primitive.ObjectID, error) {
+	log, err := sc.adjustmentLog(sctx, a)
+	if err != nil {
+		return log.ID, err
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	if len(a.Lines) > 0 {
+		for _, al := range a.Lines {
+			i := 0
+			for i < len(log.Lines) && log.Lines[i].ProductID != al.ProductID {
+				i++
+			}
+			if i == len(log.Lines) {
+				log.Lines = append(log.Lines, models.SalesLogLine{ProductID: al.ProductID, SKU: al.SKU})
+			}
+			log.Lines[i].Adjustment += sign * al.Quantity
+		}
+		set["lines"] = log.Lines
+	} else {
+		set["adjustment"] = log.Adjustment + sign*a.Quantity
+	}
+
+	_, err = sc.SalesColl.UpdateByID(sctx, log.ID, bson.M{"$set": set})
+	return log.ID, err
+}
+
+// resolveAdjustmentLines matches posted adjustment lines to catalogue
+// products.
+func (sc *SalesController) resolveAdjustmentLines(cctx context.Context, workspace primitive.ObjectID, reqs []models.AdjustmentLineRequest) ([]models.AdjustmentLine, error) {
+	lineReqs := make([]models.SalesLogLineRequest, len(reqs))
+	for i, r := range reqs {
+		if r.Quantity == 0 {
+			return nil, &lineError{"every line needs a non-zero quantity"}
+		}
+		lineReqs[i] = models.SalesLogLineRequest{SKU: r.SKU}
+	}
+
+	resolved, err := sc.resolveLines(cctx, workspace, lineReqs, nil)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]models.AdjustmentLine, len(resolved))
+	for i, l := range resolved {
+		lines[i] = models.AdjustmentLine{ProductID: l.ProductID, SKU: l.SKU, Quantity: reqs[i].Quantity}
+	}
+	return lines, nil
+}
+
+// adjustmentFilter builds the store_id, from and to filter the adjustment
+// endpoints share, writing a 400 and returning false on bad input.
+func adjustmentFilter(ctx iris.Context) (bson.M, bool) {
+	filter := scoped(ctx, bson.M{})
+	if v := ctx.URLParam("store_id"); v != "" {
+		storeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid store_id", nil)
+			return nil, false
+		}
+		filter["store_id"] = storeID
+	}
+	dates := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		dates["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		dates["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(dates) > 0 {
+		filter["date"] = dates
+	}
+	return filter, true
+}
@@ -18,11 +18,15 @@ import (
 
 type AirbnbController struct {
 	AirbnbColl *mongo.Collection
+	UserColl   *mongo.Collection
+	Audit      *Auditor
 }
 
-func NewAirbnbController(airbnbColl *mongo.Collection) *AirbnbController {
+func NewAirbnbController(airbnbColl, userColl *mongo.Collection, audit *Auditor) *AirbnbController {
 	return &AirbnbController{
 		AirbnbColl: airbnbColl,
+		UserColl:   userColl,
+		Audit:      audit,
 	}
 }
 
@@ -38,14 +42,12 @@ func (ac *AirbnbController) GetAllStatements(ctx iris.Context) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{}
+	filter := scoped(ctx, bson.M{})
 	if search != "" {
-		filter = bson.M{
-			"$or": []bson.M{
-				{"transaction": bson.M{"$regex": search, "$options": "i"}},
-				{"type": bson.M{"$regex": search, "$options": "i"}},
-				{"remark": bson.M{"$regex": search, "$options": "i"}},
-			},
+		filter["$or"] = []bson.M{
+			{"transaction": bson.M{"$regex": search, "$options": "i"}},
+			{"type": bson.M{"$regex": search, "$options": "i"}},
+			{"remark": bson.M{"$regex": search, "$options": "i"}},
 		}
 	}
 
@@ -88,7 +90,12 @@ func (ac *AirbnbController) GetAllStatements(ctx iris.Context) {
 		return
 	}
 
-	allCursor, err := ac.AirbnbColl.Find(timeoutCtx, bson.M{})
+	if err := ac.expandUsers(ctx, timeoutCtx, statements); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
+		return
+	}
+
+	allCursor, err := ac.AirbnbColl.Find(timeoutCtx, scoped(ctx, bson.M{}))
 	if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch all statements: "+err.Error(), nil)
 		return
@@ -101,71 +108,9 @@ func (ac *AirbnbController) GetAllStatements(ctx iris.Context) {
 		return
 	}
 
-	var airbnbTotal, directTotal float64
-	expenseTotals := map[string]float64{
-		"rent":          0,
-		"electricity":   0,
-		"water":         0,
-		"internet":      0,
-		"house_keeping": 0,
-		"miscellaneous": 0,
-	}
-
-	for _, s := range all {
-		transaction := strings.ToLower(s.Transaction)
-		typ := strings.ToLower(s.Type)
-
-		switch transaction {
-		case "booking":
-			switch typ {
-			case "airbnb":
-				airbnbTotal += float64(s.Amount)
-			case "direct":
-				directTotal += float64(s.Amount)
-			}
-		case "expense":
-			switch typ {
-			case "rent":
-				expenseTotals["rent"] += float64(s.Amount)
-			case "electricity":
-				expenseTotals["electricity"] += float64(s.Amount)
-			case "water":
-				expenseTotals["water"] += float64(s.Amount)
-			case "internet":
-				expenseTotals["internet"] += float64(s.Amount)
-			case "house_keeping":
-				expenseTotals["house_keeping"] += float64(s.Amount)
-			case "miscellaneous":
-				expenseTotals["miscellaneous"] += float64(s.Amount)
-			default:
-				expenseTotals["miscellaneous"] += float64(s.Amount)
-			}
-		}
-	}
-
-	totalBooking := airbnbTotal + directTotal
-	totalExpense := 0.0
-	for _, v := range expenseTotals {
-		totalExpense += v
-	}
-	netBalance := totalBooking - totalExpense
-
-	summary := iris.Map{
-		"bookings": iris.Map{
-			"airbnb_total": airbnbTotal,
-			"direct_total": directTotal,
-			"total":        totalBooking,
-		},
-		"expenses": iris.Map{
-			"rent":          expenseTotals["rent"],
-			"electricity":   expenseTotals["electricity"],
-			"water":         expenseTotals["water"],
-			"internet":      expenseTotals["internet"],
-			"house_keeping": expenseTotals["house_keeping"],
-			"miscellaneous": expenseTotals["miscellaneous"],
-			"total":         math.Round(totalExpense*100) / 100,
-		},
-		"net_balance": netBalance,
+	var summary statementSummary
+	for _, st := range all {
+		summary.add(st)
 	}
 
 	utils.SendResponse(ctx, http.StatusOK, "success", "Statements fetched successfully!", iris.Map{
@@ -174,7 +119,7 @@ func (ac *AirbnbController) GetAllStatements(ctx iris.Context) {
 		"page":        page,
 		"limit":       limit,
 		"total_pages": totalPages,
-		"summary":     summary,
+		"summary":     summary.toMap(),
 	})
 }
 
@@ -186,6 +131,12 @@ func (ac *AirbnbController) AddStatement(ctx iris.Context) {
 	}
 
 	now := time.Now().UTC()
+	statement.ID = primitive.NilObjectID
+	statement.WorkspaceID = workspaceID(ctx)
+	statement.CreatedUser = ""
+	statement.UpdatedUser = ""
+	statement.CreatedBy = currentUserID(ctx)
+	statement.UpdatedBy = currentUserID(ctx)
 	statement.CreatedAt = now
 	statement.UpdatedAt = now
 
@@ -213,6 +164,9 @@ func (ac *AirbnbController) AddStatement(ctx iris.Context) {
 		return
 	}
 
+	statement.ID, _ = result.InsertedID.(primitive.ObjectID)
+	ac.Audit.Record(ctx, models.AuditCreate, ac.AirbnbColl.Name(), statement.ID, nil, statement)
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "Statement created successfully!", iris.Map{
 		"id": result.InsertedID,
 	})
@@ -229,16 +183,17 @@ func (ac *AirbnbController) DeleteStatementByID(ctx iris.Context) {
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	res, err := ac.AirbnbColl.DeleteOne(ctxTimeout, bson.M{"_id": objectID})
-	if err != nil {
+	var deleted models.AirbnbStatement
+	err = ac.AirbnbColl.FindOneAndDelete(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Statement not found", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
 
-	if res.DeletedCount == 0 {
-		utils.SendResponse(ctx, http.StatusNotFound, "error", "Statement not found", nil)
-		return
-	}
+	ac.Audit.Record(ctx, models.AuditDelete, ac.AirbnbColl.Name(), objectID, deleted, nil)
 
 	utils.SendResponse(ctx, http.StatusOK, "success", "Statement deleted successfully!", iris.Map{"id": objectID})
 }
@@ -280,34 +235,118 @@ func (ac *AirbnbController) UpdateStatementByID(ctx iris.Context) {
 
 	update := bson.M{
 		"$set": bson.M{
-			"transaction":  statement.Transaction,
-			"type":         statement.Type,
-			"date_info":    statement.Date,
-			"nights":       statement.Nights,
-			"amount":       statement.Amount,
-			"remark":       statement.Remark,
-			"updated_user": statement.UpdatedUser,
-			"updated_at":   statement.UpdatedAt,
+			"transaction": statement.Transaction,
+			"type":        statement.Type,
+			"date_info":   statement.Date,
+			"nights":      statement.Nights,
+			"amount":      statement.Amount,
+			"remark":      statement.Remark,
+			"updated_by":  currentUserID(ctx),
+			"updated_at":  statement.UpdatedAt,
 		},
 	}
 
-	result, err := ac.AirbnbColl.UpdateByID(timeoutCtx, objectID, update)
-	if err != nil {
-		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to update statement: "+err.Error(), nil)
-		return
-	}
-
-	if result.MatchedCount == 0 {
+	var previous models.AirbnbStatement
+	err = ac.AirbnbColl.FindOneAndUpdate(timeoutCtx, scoped(ctx, bson.M{"_id": objectID}), update).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
 		utils.SendResponse(ctx, http.StatusNotFound, "error", "Statement not found", nil)
 		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to update statement: "+err.Error(), nil)
+		return
 	}
 
 	var updatedStatement models.AirbnbStatement
-	err = ac.AirbnbColl.FindOne(timeoutCtx, bson.M{"_id": objectID}).Decode(&updatedStatement)
+	err = ac.AirbnbColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&updatedStatement)
 	if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
 
+	ac.Audit.Record(ctx, models.AuditUpdate, ac.AirbnbColl.Name(), objectID, previous, updatedStatement)
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "Statement updated successfully!", updatedStatement)
 }
+
+// expandUsers fills in the names of who created and last updated each
+// statement.
+func (ac *AirbnbController) expandUsers(ctx iris.Context, cctx context.Context, statements []models.AirbnbStatement) error {
+	ids := make([]primitive.ObjectID, 0, 2*len(statements))
+	for _, s := range statements {
+		ids = append(ids, s.CreatedBy, s.UpdatedBy)
+	}
+	refs, err := userRefs(ctx, cctx, ac.UserColl, ids...)
+	if err != nil {
+		return err
+	}
+	for i := range statements {
+		statements[i].CreatedByUser = refs[statements[i].CreatedBy]
+		statements[i].UpdatedByUser = refs[statements[i].UpdatedBy]
+	}
+	return nil
+}
+
+// statementSummary totals statements by booking channel and expense
+// category, for GetAllStatements and the exports alike.
+type statementSummary struct {
+	airbnb, direct float64
+	expenses       map[string]float64
+}
+
+// expenseCategories are the summary's expense buckets; other types count as
+// miscellaneous.
+var expenseCategories = []string{"rent", "electricity", "water", "internet", "house_keeping", "miscellaneous"}
+
+func (s *statementSummary) add(st models.AirbnbStatement) {
+	if s.expenses == nil {
+		s.expenses = make(map[string]float64, len(expenseCategories))
+		for _, c := range expenseCategories {
+			s.expenses[c] = 0
+		}
+	}
+
+	typ := strings.ToLower(st.Type)
+	switch strings.ToLower(st.Transaction) {
+	case "booking":
+		switch typ {
+		case "airbnb":
+			s.airbnb += st.Amount
+		case "direct":
+			s.direct += st.Amount
+		}
+	case "expense":
+		if _, ok := s.expenses[typ]; ok {
+			s.expenses[typ] += st.Amount
+		} else {
+			s.expenses["miscellaneous"] += st.Amount
+		}
+	}
+}
+
+func (s *statementSummary) bookings() float64 {
+	return s.airbnb + s.direct
+}
+
+func (s *statementSummary) totalExpense() float64 {
+	total := 0.0
+	for _, c := range expenseCategories {
+		total += s.expenses[c]
+	}
+	return total
+}
+
+func (s *statementSummary) toMap() iris.Map {
+	expenses := iris.Map{"total": math.Round(s.totalExpense()*100) / 100}
+	for _, c := range expenseCategories {
+		expenses[c] = s.expenses[c]
+	}
+	return iris.Map{
+		"bookings": iris.Map{
+			"airbnb_total": s.airbnb,
+			"direct_total": s.direct,
+			"total":        s.bookings(),
+		},
+		"expenses":    expenses,
+		"net_balance": s.bookings() - s.totalExpense(),
+	}
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var statementExportColumns = []string{"Recorded", "Transaction", "Type", "From", "To", "Nights", "Amount", "Remark"}
+
+// ExportStatements → GET /statement/export
+// Takes GetAllStatements' search plus from/to (YYYY-MM-DD) and format (csv,
+// xlsx or pdf), without paging. Rows are streamed from the cursor. XLSX and PDF end with a
+// summary of the exported statements; CSV holds the statements only.
+func (ac *AirbnbController) ExportStatements(ctx iris.Context) {
+	filter, ok := statementExportFilter(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := exportContext(ctx)
+	defer cancel()
+
+	cursor, err := ac.AirbnbColl.Find(cctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetBatchSize(500),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch statements: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(cctx)
+
+	format, ok := startExport(ctx, "airbnb-statements")
+	if !ok {
+		return
+	}
+
+	// Headers are already sent, so failures past here can only be logged.
+	switch format {
+	case "csv":
+		err = exportStatementsCSV(ctx, cctx, cursor)
+	case "xlsx":
+		err = exportStatementsXLSX(ctx, cctx, cursor)
+	case "pdf":
+		err = exportStatementsPDF(ctx, cctx, cursor)
+	}
+	if err != nil {
+		log.Println("statement export failed:", err)
+	}
+}
+
+// statementDates returns a statement's stay or bill dates, blank if unset.
+func statementDates(st models.AirbnbStatement) (string, string) {
+	day := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.In(utils.BusinessLocation()).Format("2006-01-02")
+	}
+	return day(st.Date.Start), day(st.Date.End)
+}
+
+func exportStatementsCSV(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor) error {
+	w := csv.NewWriter(ctx.ResponseWriter())
+	if err := w.Write(statementExportColumns); err != nil {
+		return err
+	}
+
+	for n := 1; cursor.Next(cctx); n++ {
+		var st models.AirbnbStatement
+		if err := cursor.Decode(&st); err != nil {
+			return err
+		}
+		from, to := statementDates(st)
+		if err := w.Write([]string{
+			st.CreatedAt.In(utils.BusinessLocation()).Format("2006-01-02 15:04"),
+			st.Transaction, st.Type, from, to, strconv.Itoa(st.Nights),
+			strconv.FormatFloat(st.Amount, 'f', 2, 64), st.Remark,
+		}); err != nil {
+			return err
+		}
+		if n%exportFlushEvery == 0 {
+			w.Flush()
+			ctx.ResponseWriter().Flush()
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return cursor.Err()
+}
+
+func exportStatementsXLSX(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+	if err := f.SetSheetName("Sheet1", "Statements"); err != nil {
+		return err
+	}
+
+	sheet, err := f.NewStreamWriter("Statements")
+	if err != nil {
+		return err
+	}
+	if err := sheet.SetRow("A1", headerRow(styles.header, statementExportColumns...)); err != nil {
+		return err
+	}
+
+	var summary statementSummary
+	row := 2
+	for cursor.Next(cctx) {
+		var st models.AirbnbStatement
+		if err := cursor.Decode(&st); err != nil {
+			return err
+		}
+		summary.add(st)
+
+		from, to := statementDates(st)
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := sheet.SetRow(cell, []interface{}{
+			excelize.Cell{StyleID: styles.date, Value: st.CreatedAt.In(utils.BusinessLocation())},
+			st.Transaction, st.Type, from, to, st.Nights,
+			excelize.Cell{StyleID: styles.inr, Value: st.Amount},
+			st.Remark,
+		}); err != nil {
+			return err
+		}
+		row++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if err := sheet.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := f.NewSheet("Summary"); err != nil {
+		return err
+	}
+	f.SetCellValue("Summary", "A1", exportPeriod(ctx))
+	f.SetCellStyle("Summary", "A1", "A1", styles.header)
+	for i, line := range summary.lines() {
+		r := strconv.Itoa(i + 3)
+		f.SetCellValue("Summary", "A"+r, line.label)
+		f.SetCellValue("Summary", "B"+r, line.amount)
+		f.SetCellStyle("Summary", "B"+r, "B"+r, styles.inr)
+		if line.total {
+			f.SetCellStyle("Summary", "A"+r, "A"+r, styles.header)
+		}
+	}
+	f.SetColWidth("Summary", "A", "A", 28)
+	f.SetColWidth("Summary", "B", "B", 18)
+
+	_, err = f.WriteTo(ctx.ResponseWriter())
+	return err
+}
+
+func exportStatementsPDF(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor) error {
+	report := utils.NewPDFReport("Airbnb statements", exportPeriod(ctx), []utils.PDFColumn{
+		{Title: "Recorded", Width: 26, Align: "L"},
+		{Title: "Transaction", Width: 26, Align: "L"},
+		{Title: "Type", Width: 30, Align: "L"},
+		{Title: "From", Width: 24, Align: "L"},
+		{Title: "To", Width: 24, Align: "L"},
+		{Title: "Nights", Width: 16, Align: "R"},
+		{Title: "Amount", Width: 36, Align: "R"},
+		{Title: "Remark", Width: 95, Align: "L"},
+	})
+
+	var summary statementSummary
+	for cursor.Next(cctx) {
+		var st models.AirbnbStatement
+		if err := cursor.Decode(&st); err != nil {
+			return err
+		}
+		summary.add(st)
+
+		from, to := statementDates(st)
+		report.Row(
+			st.CreatedAt.In(utils.BusinessLocation()).Format("02 Jan 2006"),
+			st.Transaction, st.Type, from, to, strconv.Itoa(st.Nights),
+			utils.FormatINR(st.Amount), st.Remark,
+		)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	pairs := make([][2]string, 0, 12)
+	for _, line := range summary.lines() {
+		pairs = append(pairs, [2]string{line.label, utils.FormatINR(line.amount)})
+	}
+	report.Summary("Summary", pairs)
+
+	return report.Write(ctx.ResponseWriter())
+}
+
+// summaryLine is one labelled amount of a statement summary in an export.
+type summaryLine struct {
+	label  string
+	amount float64
+	total  bool
+}
+
+func (s *statementSummary) lines() []summaryLine {
+	lines := []summaryLine{
+		{label: "Airbnb bookings", amount: s.airbnb},
+		{label: "Direct bookings", amount: s.direct},
+		{label: "Total bookings", amount: s.bookings(), total: true},
+	}
+	for _, c := range expenseCategories {
+		label := strings.ToUpper(c[:1]) + strings.ReplaceAll(c[1:], "_", " ")
+		lines = append(lines, summaryLine{label: label, amount: s.expenses[c]})
+	}
+	return append(lines,
+		summaryLine{label: "Total expenses", amount: s.totalExpense(), total: true},
+		summaryLine{label: "Net balance", amount: s.bookings() - s.totalExpense(), total: true},
+	)
+}
+
+// statementExportFilter matches search the way GetAllStatements does, and
+// from/to (YYYY-MM-DD) bound when the statement was recorded so a report can
+// cover one period. It writes a 400 and returns false on bad input.
+func statementExportFilter(ctx iris.Context) (bson.M, bool) {
+	filter := scoped(ctx, bson.M{})
+	if search := ctx.URLParam("search"); search != "" {
+		filter["$or"] = []bson.M{
+			{"transaction": bson.M{"$regex": search, "$options": "i"}},
+			{"type": bson.M{"$regex": search, "$options": "i"}},
+			{"remark": bson.M{"$regex": search, "$options": "i"}},
+		}
+	}
+	created := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, utils.BusinessLocation())
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		created["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, utils.BusinessLocation())
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		created["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter, true
+}
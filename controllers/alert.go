@@ -0,0 +1,633 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxAlertEmails caps the addresses one rule mails, across its channels.
+const maxAlertEmails = 10
+
+// AlertNotifier delivers an alert through one kind of channel and returns
+// who it was delivered to.
+type AlertNotifier interface {
+	Notify(cctx context.Context, alert models.Alert, storeName string, ch models.AlertChannel) (string, error)
+}
+
+// Alerter evaluates alert rules against written sales logs, records the
+// alerts they raise and hands them to the notifier for each channel type.
+type Alerter struct {
+	RuleColl  *mongo.Collection
+	AlertColl *mongo.Collection
+	StoreColl *mongo.Collection
+	Audit     *Auditor
+	Notifiers map[string]AlertNotifier
+}
+
+func NewAlerter(ruleColl, alertColl, storeColl *mongo.Collection, mailer utils.Mailer, audit *Auditor) *Alerter {
+	return &Alerter{
+		RuleColl:  ruleColl,
+		AlertColl: alertColl,
+		StoreColl: storeColl,
+		Audit:     audit,
+		Notifiers: map[string]AlertNotifier{
+			models.ChannelWebhook: webhookNotifier{},
+			models.ChannelEmail:   emailNotifier{Mailer: mailer},
+			models.ChannelInApp:   inAppNotifier{},
+		},
+	}
+}
+
+// Evaluate checks the workspace's enabled rules against logs and returns the
+// alerts raised. A rule doesn't fire twice for a log whose figures haven't
+// changed. Delivery happens in the background; failures are recorded on the
+// alert rather than failing the request that wrote the logs.
+func (a *Alerter) Evaluate(ctx iris.Context, logs ...models.SalesLog) []models.Alert {
+	raised := []models.Alert{}
+	if len(logs) == 0 {
+		return raised
+	}
+
+	cctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := a.RuleColl.Find(cctx, scoped(ctx, bson.M{"enabled": true}))
+	if err != nil {
+		log.Println("alerts: failed to load rules:", err)
+		return raised
+	}
+	var rules []models.AlertRule
+	if err := cursor.All(cctx, &rules); err != nil {
+		log.Println("alerts: failed to load rules:", err)
+		return raised
+	}
+	if len(rules) == 0 {
+		return raised
+	}
+
+	names, err := storeNames(cctx, a.StoreColl, workspaceID(ctx))
+	if err != nil {
+		log.Println("alerts: failed to load stores:", err)
+	}
+
+	for _, l := range logs {
+		for _, rule := range rules {
+			if rule.StoreID != nil && *rule.StoreID != l.StoreID {
+				continue
+			}
+			message, breached := breach(rule, l)
+			if !breached {
+				continue
+			}
+
+			count, err := a.AlertColl.CountDocuments(cctx, bson.M{
+				"rule_id":      rule.ID,
+				"sales_log_id": l.ID,
+				"physical":     l.Physical,
+				"difference":   l.Difference,
+			})
+			if err != nil {
+				log.Println("alerts: failed to check history:", err)
+				continue
+			}
+			if count > 0 {
+				continue
+			}
+
+			alert := models.Alert{
+				ID:          primitive.NewObjectID(),
+				WorkspaceID: l.WorkspaceID,
+				RuleID:      rule.ID,
+				RuleName:    rule.Name,
+				Kind:        rule.Kind,
+				Threshold:   rule.Threshold,
+				StoreID:     l.StoreID,
+				SalesLogID:  l.ID,
+				Date:        l.Date,
+				Opening:     l.Opening,
+				Physical:    l.Physical,
+				Difference:  l.Difference,
+				Message:     message,
+				Deliveries:  []models.AlertDelivery{},
+				CreatedAt:   time.Now().UTC(),
+			}
+			if _, err := a.AlertColl.InsertOne(cctx, alert); err != nil {
+				log.Println("alerts: failed to record alert:", err)
+				continue
+			}
+			raised = append(raised, alert)
+			go a.deliver(alert, names[l.StoreID], rule.Channels)
+		}
+	}
+	return raised
+}
+
+// breach reports whether log breaks rule, and how.
+func breach(rule models.AlertRule, l models.SalesLog) (string, bool) {
+	day := utils.DayKey(l.Date)
+	switch rule.Kind {
+	case models.AlertAbsoluteDifference:
+		if math.Abs(float64(l.Difference)) > rule.Threshold {
+			return fmt.Sprintf("Difference on %s is %d, beyond the allowed %g", day, l.Difference, rule.Threshold), true
+		}
+	case models.AlertRelativeDifference:
+		if l.Difference == 0 {
+			return "", false
+		}
+		if l.Opening == 0 {
+			return fmt.Sprintf("Difference on %s is %d against an opening of 0", day, l.Difference), true
+		}
+		pct := math.Abs(float64(l.Difference)) / math.Abs(float64(l.Opening)) * 100
+		if pct > rule.Threshold {
+			return fmt.Sprintf("Difference on %s is %d, %.1f%% of opening stock, beyond the allowed %g%%", day, l.Difference, pct, rule.Threshold), true
+		}
+	case models.AlertNegativePhysical:
+		if l.Physical < 0 {
+			return fmt.Sprintf("Physical stock on %s is %d", day, l.Physical), true
+		}
+	}
+	return "", false
+}
+
+// deliver sends alert through each channel and records the outcomes.
+func (a *Alerter) deliver(alert models.Alert, storeName string, channels []models.AlertChannel) {
+	cctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deliveries := make([]models.AlertDelivery, 0, len(channels))
+	for _, ch := range channels {
+		d := models.AlertDelivery{Channel: ch.Type, Status: "sent"}
+		notifier, ok := a.Notifiers[ch.Type]
+		if !ok {
+			d.Status, d.Error = "failed", "no notifier for this channel"
+		} else if target, err := notifier.Notify(cctx, alert, storeName, ch); err != nil {
+			d.Target, d.Status, d.Error = target, "failed", err.Error()
+		} else {
+			d.Target = target
+		}
+		d.At = time.Now().UTC()
+		deliveries = append(deliveries, d)
+	}
+
+	_, err := a.AlertColl.UpdateByID(cctx, alert.ID, bson.M{"$push": bson.M{"deliveries": bson.M{"$each": deliveries}}})
+	if err != nil {
+		log.Println("alerts: failed to record deliveries:", err)
+	}
+}
+
+// webhookNotifier POSTs the alert as JSON, signed with the channel secret.
+// Errors are reduced to a coarse reason before they are stored on the alert,
+// so delivery history can't be used to probe the network.
+type webhookNotifier struct{}
+
+func (webhookNotifier) Notify(cctx context.Context, alert models.Alert, storeName string, ch models.AlertChannel) (string, error) {
+	err := utils.PostWebhook(cctx, ch.URL, ch.Secret, "stock.alert", iris.Map{
+		"event":      "stock.alert",
+		"alert":      alert,
+		"store_name": storeName,
+	})
+	var netErr net.Error
+	switch {
+	case err == nil:
+	case errors.Is(err, utils.ErrWebhookAddress):
+		err = utils.ErrWebhookAddress
+	case errors.As(err, &netErr) && netErr.Timeout():
+		err = errors.New("webhook timed out")
+	case strings.HasPrefix(err.Error(), "webhook answered"):
+	default:
+		log.Println("alerts: webhook delivery failed:", err)
+		err = errors.New("webhook could not be reached")
+	}
+	return ch.URL, err
+}
+
+// emailNotifier mails the alert to every address on the channel.
+type emailNotifier struct {
+	Mailer utils.Mailer
+}
+
+func (n emailNotifier) Notify(cctx context.Context, alert models.Alert, storeName string, ch models.AlertChannel) (string, error) {
+	body := "Stock alert: " + alert.RuleName + "\n\n" +
+		"Store: " + storeName + "\n" +
+		"Day: " + utils.DayKey(alert.Date) + "\n" +
+		alert.Message + ".\n\n" +
+		fmt.Sprintf("Opening %d, physical %d, difference %d.\n\n", alert.Opening, alert.Physical, alert.Difference) +
+		"Review and acknowledge it in Pai Finance: " + utils.AppURL() + "/vhiw/alerts\n"
+
+	for _, to := range ch.Emails {
+		err := n.Mailer.Send(utils.Mail{
+			To:      to,
+			Subject: "Stock alert: " + alert.RuleName + " (" + utils.DayKey(alert.Date) + ")",
+			Body:    body,
+		})
+		if err != nil {
+			return to, err
+		}
+	}
+	return strings.Join(ch.Emails, ", "), nil
+}
+
+// inAppNotifier needs no delivery: open alerts are listed by GetAlerts.
+type inAppNotifier struct{}
+
+func (inAppNotifier) Notify(context.Context, models.Alert, string, models.AlertChannel) (string, error) {
+	return "", nil
+}
+
+// GetAlertRules → GET /alerts/rules
+// Webhook secrets are never listed.
+func (a *Alerter) GetAlertRules(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := a.RuleColl.Find(timeoutCtx, scoped(ctx, bson.M{}), options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch alert rules: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	rules := []models.AlertRule{}
+	if err := cursor.All(timeoutCtx, &rules); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode alert rules: "+err.Error(), nil)
+		return
+	}
+	for i := range rules {
+		hideSecrets(&rules[i])
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Alert rules fetched successfully!", rules)
+}
+
+// AddAlertRule → POST /alerts/rules
+// Webhook channels without a secret get a generated one, returned only in
+// this response.
+func (a *Alerter) AddAlertRule(ctx iris.Context) {
+	var req models.AlertRuleRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	rule := models.AlertRule{
+		WorkspaceID: workspaceID(ctx),
+		Enabled:     true,
+		CreatedBy:   currentUserID(ctx),
+		UpdatedBy:   currentUserID(ctx),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if !a.applyRuleRequest(ctx, timeoutCtx, &rule, req, nil) {
+		return
+	}
+
+	result, err := a.RuleColl.InsertOne(timeoutCtx, rule)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert alert rule: "+err.Error(), nil)
+		return
+	}
+	rule.ID = result.InsertedID.(primitive.ObjectID)
+
+	audited := rule
+	hideSecrets(&audited)
+	a.Audit.Record(ctx, models.AuditCreate, a.RuleColl.Name(), rule.ID, nil, audited)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Alert rule created successfully!", rule)
+}
+
+// UpdateAlertRuleByID → PUT /alerts/rules/{id}
+// Replaces the rule. A webhook posted without a secret keeps the secret of
+// the existing webhook with the same URL, or gets a new one.
+func (a *Alerter) UpdateAlertRuleByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	var req models.AlertRuleRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var rule models.AlertRule
+	if err := a.RuleColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&rule); err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Alert rule not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	before := rule
+	before.Channels = append([]models.AlertChannel(nil), rule.Channels...)
+	hideSecrets(&before)
+
+	previous := rule.Channels
+	rule.StoreID = nil
+	if !a.applyRuleRequest(ctx, timeoutCtx, &rule, req, previous) {
+		return
+	}
+	rule.UpdatedBy = currentUserID(ctx)
+	rule.UpdatedAt = time.Now().UTC()
+
+	if _, err := a.RuleColl.ReplaceOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID}), rule); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to update alert rule: "+err.Error(), nil)
+		return
+	}
+
+	after := rule
+	after.Channels = append([]models.AlertChannel(nil), rule.Channels...)
+	hideSecrets(&after)
+	a.Audit.Record(ctx, models.AuditUpdate, a.RuleColl.Name(), objectID, before, after)
+
+	// Only secrets generated by this request are shown.
+	for i, ch := range rule.Channels {
+		for _, p := range previous {
+			if ch.Type == models.ChannelWebhook && p.URL == ch.URL && p.Secret == ch.Secret {
+				rule.Channels[i].Secret = ""
+			}
+		}
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Alert rule updated successfully!", rule)
+}
+
+// DeleteAlertRuleByID → DELETE /alerts/rules/{id}
+// Alerts the rule already raised are kept.
+func (a *Alerter) DeleteAlertRuleByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var deleted models.AlertRule
+	err = a.RuleColl.FindOneAndDelete(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Alert rule not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+	hideSecrets(&deleted)
+	a.Audit.Record(ctx, models.AuditDelete, a.RuleColl.Name(), objectID, deleted, nil)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Alert rule deleted successfully!", iris.Map{"id": objectID})
+}
+
+// GetAlerts → GET /alerts
+// Filters: acknowledged (true/false), store_id, rule_id, from and to
+// (YYYY-MM-DD log days). Newest first; "open" counts unacknowledged alerts
+// for the in-app badge.
+func (a *Alerter) GetAlerts(ctx iris.Context) {
+	page, _ := ctx.URLParamInt("page")
+	limit, _ := ctx.URLParamInt("limit")
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	filter := scoped(ctx, bson.M{})
+	for param, field := range map[string]string{"store_id": "store_id", "rule_id": "rule_id"} {
+		if v := ctx.URLParam(param); v != "" {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid "+param, nil)
+				return
+			}
+			filter[field] = id
+		}
+	}
+	if v := ctx.URLParam("acknowledged"); v != "" {
+		filter["acknowledged"] = v == "true"
+	}
+
+	dates := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		dates["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		dates["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(dates) > 0 {
+		filter["date"] = dates
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	total, err := a.AlertColl.CountDocuments(timeoutCtx, filter)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to count alerts: "+err.Error(), nil)
+		return
+	}
+	open, err := a.AlertColl.CountDocuments(timeoutCtx, scoped(ctx, bson.M{"acknowledged": false}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to count alerts: "+err.Error(), nil)
+		return
+	}
+
+	cursor, err := a.AlertColl.Find(timeoutCtx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch alerts: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	alerts := []models.Alert{}
+	if err := cursor.All(timeoutCtx, &alerts); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode alerts: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched alerts successfully!", iris.Map{
+		"items":       alerts,
+		"open":        open,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+	})
+}
+
+// AcknowledgeAlert → POST /alerts/{id}/acknowledge
+func (a *Alerter) AcknowledgeAlert(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	var req models.AcknowledgeAlertRequest
+	if err := ctx.ReadJSON(&req); err != nil && ctx.GetContentLength() > 0 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var alert models.Alert
+	err = a.AlertColl.FindOneAndUpdate(timeoutCtx,
+		scoped(ctx, bson.M{"_id": objectID, "acknowledged": false}),
+		bson.M{"$set": bson.M{
+			"acknowledged":    true,
+			"acknowledged_by": currentUserID(ctx),
+			"acknowledged_at": time.Now().UTC(),
+			"note":            strings.TrimSpace(req.Note),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&alert)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Alert not found or already acknowledged", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	a.Audit.Record(ctx, models.AuditUpdate, a.AlertColl.Name(), objectID, nil, alert)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Alert acknowledged!", alert)
+}
+
+// applyRuleRequest validates req onto rule, writing a 400 and returning
+// false on bad input. previous holds the channels being replaced.
+func (a *Alerter) applyRuleRequest(ctx iris.Context, cctx context.Context, rule *models.AlertRule, req models.AlertRuleRequest, previous []models.AlertChannel) bool {
+	fail := func(msg string) bool {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", msg, nil)
+		return false
+	}
+
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Kind = req.Kind
+	rule.Threshold = req.Threshold
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if rule.Name == "" {
+		return fail("name is required")
+	}
+	switch rule.Kind {
+	case models.AlertAbsoluteDifference, models.AlertRelativeDifference:
+		if rule.Threshold < 0 {
+			return fail("threshold can't be negative")
+		}
+	case models.AlertNegativePhysical:
+		rule.Threshold = 0
+	default:
+		return fail("kind must be " + models.AlertAbsoluteDifference + ", " + models.AlertRelativeDifference + " or " + models.AlertNegativePhysical)
+	}
+
+	if req.StoreID != "" {
+		store, err := findStore(cctx, a.StoreColl, rule.WorkspaceID, req.StoreID)
+		if err != nil {
+			return fail("Unknown store_id")
+		}
+		rule.StoreID = &store.ID
+	}
+
+	if len(req.Channels) == 0 {
+		return fail("at least one channel is required")
+	}
+	rule.Channels = make([]models.AlertChannel, 0, len(req.Channels))
+	emails := 0
+	for _, ch := range req.Channels {
+		switch ch.Type {
+		case models.ChannelWebhook:
+			if err := utils.ValidateWebhookURL(cctx, ch.URL); err != nil {
+				return fail(err.Error())
+			}
+			if ch.Secret == "" {
+				for _, p := range previous {
+					if p.Type == models.ChannelWebhook && p.URL == ch.URL {
+						ch.Secret = p.Secret
+					}
+				}
+			}
+			if ch.Secret == "" {
+				secret, err := utils.GenerateOpaqueToken(32)
+				if err != nil {
+					utils.SendResponse(ctx, http.StatusInternalServerError, "error", "failed to generate webhook secret", nil)
+					return false
+				}
+				ch.Secret = secret
+			}
+			ch.Emails = nil
+		case models.ChannelEmail:
+			if len(ch.Emails) == 0 {
+				return fail("email channels need at least one address")
+			}
+			emails += len(ch.Emails)
+			if emails > maxAlertEmails {
+				return fail(fmt.Sprintf("a rule can email at most %d addresses", maxAlertEmails))
+			}
+			for i, e := range ch.Emails {
+				addr, err := mail.ParseAddress(strings.TrimSpace(e))
+				if err != nil {
+					return fail("Invalid email address " + e)
+				}
+				ch.Emails[i] = strings.ToLower(addr.Address)
+			}
+			ch.URL, ch.Secret = "", ""
+		case models.ChannelInApp:
+			ch = models.AlertChannel{Type: models.ChannelInApp}
+		default:
+			return fail("channel type must be webhook, email or in_app")
+		}
+		rule.Channels = append(rule.Channels, ch)
+	}
+	return true
+}
+
+func hideSecrets(rule *models.AlertRule) {
+	for i := range rule.Channels {
+		rule.Channels[i].Secret = ""
+	}
+}
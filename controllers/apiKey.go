@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyController struct {
+	APIKeyColl *mongo.Collection
+}
+
+func NewAPIKeyController(apiKeyColl *mongo.Collection) *APIKeyController {
+	return &APIKeyController{
+		APIKeyColl: apiKeyColl,
+	}
+}
+
+// GetAPIKeys → GET /auth/api-keys
+func (kc *APIKeyController) GetAPIKeys(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := kc.APIKeyColl.Find(timeoutCtx,
+		bson.M{"user_id": currentUserID(ctx)},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch API keys: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	apiKeys := []models.APIKey{}
+	if err := cursor.All(timeoutCtx, &apiKeys); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode API keys: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "API keys fetched successfully!", apiKeys)
+}
+
+// AddAPIKey → POST /auth/api-keys
+// The full key is only ever returned in this response.
+func (kc *APIKeyController) AddAPIKey(ctx iris.Context) {
+	var req models.APIKeyRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "name is required", nil)
+		return
+	}
+	scopes := []string{}
+	for _, s := range req.Scopes {
+		if !models.IsValidAPIKeyScope(s) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid scope: "+s, nil)
+			return
+		}
+		scopes = append(scopes, s)
+	}
+
+	key, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to generate API key", nil)
+		return
+	}
+
+	apiKey := models.APIKey{
+		UserID:      currentUserID(ctx),
+		WorkspaceID: workspaceID(ctx),
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(key),
+		Scopes:      scopes,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := kc.APIKeyColl.InsertOne(timeoutCtx, apiKey)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert API key: "+err.Error(), nil)
+		return
+	}
+	apiKey.ID = result.InsertedID.(primitive.ObjectID)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "API key created! Copy it now, it won't be shown again.", iris.Map{
+		"key":     key,
+		"api_key": apiKey,
+	})
+}
+
+// RevokeAPIKeyByID → DELETE /auth/api-keys/{id}
+func (kc *APIKeyController) RevokeAPIKeyByID(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := kc.APIKeyColl.UpdateOne(ctxTimeout,
+		bson.M{"_id": objectID, "user_id": currentUserID(ctx), "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	if res.MatchedCount == 0 {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "API key not found", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "API key revoked successfully!", iris.Map{"id": objectID})
+}
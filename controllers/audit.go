@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Auditor writes the audit log. Controllers call Record after each
+// successful create, update or delete.
+type Auditor struct {
+	AuditColl *mongo.Collection
+}
+
+func NewAuditor(auditColl *mongo.Collection) *Auditor {
+	return &Auditor{
+		AuditColl: auditColl,
+	}
+}
+
+// auditIgnored fields change on every write or never change, so they only
+// add noise to a diff.
+var auditIgnored = map[string]bool{
+	"_id":          true,
+	"workspace_id": true,
+	"created_at":   true,
+	"updated_at":   true,
+	"updated_by":   true,
+}
+
+// auditRedacted fields are secrets; the log notes that they changed but not
+// their values.
+var auditRedacted = map[string]bool{
+	"password": true,
+	"totp":     true,
+}
+
+// Record logs action on document id in collection. before is nil for
+// creates and after is nil for deletes. A failed write is logged rather than
+// failing the request, since the mutation itself has already happened.
+func (a *Auditor) Record(ctx iris.Context, action, collection string, id primitive.ObjectID, before, after interface{}) {
+	entry := models.AuditEntry{
+		WorkspaceID: workspaceID(ctx),
+		ActorID:     currentUserID(ctx),
+		Action:      action,
+		Collection:  collection,
+		DocumentID:  id,
+		Changes:     auditDiff(before, after),
+		IP:          ctx.RemoteAddr(),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if keyID, ok := ctx.Values().Get("apiKeyID").(primitive.ObjectID); ok {
+		entry.APIKeyID = &keyID
+	}
+
+	// Not tied to the request context: the entry should still be written if
+	// the client has gone away.
+	cctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := a.AuditColl.InsertOne(cctx, entry); err != nil {
+		log.Printf("audit: failed to record %s %s/%s: %v", action, collection, id.Hex(), err)
+	}
+}
+
+// auditDiff compares the top-level fields of two documents as they would be
+// stored in Mongo.
+func auditDiff(before, after interface{}) map[string]models.AuditChange {
+	b, a := auditDocument(before), auditDocument(after)
+	changes := map[string]models.AuditChange{}
+
+	for key, av := range a {
+		if auditIgnored[key] {
+			continue
+		}
+		bv, ok := b[key]
+		if ok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		changes[key] = auditChange(key, bv, av)
+	}
+	for key, bv := range b {
+		if auditIgnored[key] {
+			continue
+		}
+		if _, ok := a[key]; !ok {
+			changes[key] = auditChange(key, bv, nil)
+		}
+	}
+	return changes
+}
+
+func auditChange(key string, before, after interface{}) models.AuditChange {
+	if auditRedacted[key] {
+		if before != nil {
+			before = "[redacted]"
+		}
+		if after != nil {
+			after = "[redacted]"
+		}
+	}
+	return models.AuditChange{Before: before, After: after}
+}
+
+func auditDocument(v interface{}) bson.M {
+	doc := bson.M{}
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return doc
+	}
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return doc
+	}
+	_ = bson.Unmarshal(raw, &doc)
+	return doc
+}
+
+// GetAuditLog → GET /admin/audit
+// Filters: actor, collection, document_id, action, from and to
+// (YYYY-MM-DD business days, inclusive). Newest entries first.
+func (a *Auditor) GetAuditLog(ctx iris.Context) {
+	page, _ := ctx.URLParamInt("page")
+	limit, _ := ctx.URLParamInt("limit")
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	filter := scoped(ctx, bson.M{})
+	for param, field := range map[string]string{"actor": "actor_id", "document_id": "document_id"} {
+		if v := ctx.URLParam(param); v != "" {
+			id, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid "+param, nil)
+				return
+			}
+			filter[field] = id
+		}
+	}
+	if v := ctx.URLParam("collection"); v != "" {
+		filter["collection"] = v
+	}
+	if v := ctx.URLParam("action"); v != "" {
+		filter["action"] = v
+	}
+
+	created := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, utils.BusinessLocation())
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		created["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, utils.BusinessLocation())
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		created["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	total, err := a.AuditColl.CountDocuments(timeoutCtx, filter)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to count audit entries: "+err.Error(), nil)
+		return
+	}
+
+	cursor, err := a.AuditColl.Find(timeoutCtx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page-1)*limit)).
+		SetLimit(int64(limit)),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch audit entries: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(timeoutCtx, &entries); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode audit entries: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched audit log successfully!", iris.Map{
+		"items":       entries,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": int(math.Ceil(float64(total) / float64(limit))),
+	})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -12,22 +13,35 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthController struct {
-	UserColl      *mongo.Collection
-	TokenTTLHours int
+	UserColl        *mongo.Collection
+	WorkspaceColl   *mongo.Collection
+	RefreshColl     *mongo.Collection
+	RevokedColl     *mongo.Collection
+	ResetColl       *mongo.Collection
+	SessionColl     *mongo.Collection
+	EmailChangeColl *mongo.Collection
+	Mailer          utils.Mailer
+	Throttler       *LoginThrottler
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	ResetTokenTTL   time.Duration
+	VerifyTokenTTL  time.Duration
 }
 
 type SignupRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
-	DOB       string `json:"dob"`
-	Gender    string `json:"gender"`
-	Phone     string `json:"phone"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Email         string `json:"email"`
+	Password      string `json:"password"`
+	DOB           string `json:"dob"`
+	Gender        string `json:"gender"`
+	Phone         string `json:"phone"`
+	WorkspaceName string `json:"workspace_name"`
 }
 
 type LoginRequest struct {
@@ -35,10 +49,25 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-func NewAuthController(userColl *mongo.Collection) *AuthController {
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func NewAuthController(userColl, workspaceColl, refreshColl, revokedColl, resetColl, sessionColl, emailChangeColl *mongo.Collection, mailer utils.Mailer, throttler *LoginThrottler) *AuthController {
 	return &AuthController{
-		UserColl:      userColl,
-		TokenTTLHours: 72,
+		UserColl:        userColl,
+		WorkspaceColl:   workspaceColl,
+		RefreshColl:     refreshColl,
+		RevokedColl:     revokedColl,
+		ResetColl:       resetColl,
+		SessionColl:     sessionColl,
+		EmailChangeColl: emailChangeColl,
+		Mailer:          mailer,
+		Throttler:       throttler,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+		ResetTokenTTL:   time.Hour,
+		VerifyTokenTTL:  48 * time.Hour,
 	}
 }
 
@@ -93,40 +122,18 @@ func (ac *AuthController) Signup(ctx iris.Context) {
 		DOB:       dob,
 		Gender:    req.Gender,
 		Phone:     req.Phone,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
 	}
 
-	res, err := ac.UserColl.InsertOne(cctx, user)
-	if err != nil {
-		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to create user", nil)
+	if err := ac.createAccount(cctx, &user, req.WorkspaceName); mongo.IsDuplicateKeyError(err) {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "email already registered", nil)
 		return
-	}
-	oid := res.InsertedID.(primitive.ObjectID)
-
-	token, err := utils.CreateToken(oid, ac.TokenTTLHours)
-	if err != nil {
-		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to create token", nil)
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to create account", nil)
 		return
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Sign up successful!", iris.Map{
-		"token": token,
-		"user": iris.Map{
-			"id":         oid.Hex(),
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"email":      user.Email,
-			"phone":      user.Phone,
-			"dob": func() string {
-				if user.DOB.IsZero() {
-					return ""
-				}
-				return user.DOB.Format("2006-01-02")
-			}(),
-			"gender": user.Gender,
-		},
-	})
+	ac.sendVerificationMailAsync(user)
+	ac.sendLoginSuccess(ctx, cctx, user, "Sign up successful! Check your inbox to verify your email.")
 }
 
 func (ac *AuthController) Login(ctx iris.Context) {
@@ -144,64 +151,313 @@ func (ac *AuthController) Login(ctx iris.Context) {
 	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
 	defer cancel()
 
+	accountKey := accountThrottleKey(req.Email)
+	ipKey := ipThrottleKey(ctx.RemoteAddr())
+
+	wait, err := ac.Throttler.RetryAfter(cctx, accountKey, ipKey)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if wait > 0 {
+		sendTooManyAttempts(ctx, wait)
+		return
+	}
+
 	var user models.User
-	err := ac.UserColl.FindOne(cctx, bson.M{"email": strings.ToLower(req.Email)}).Decode(&user)
+	err = ac.UserColl.FindOne(cctx, bson.M{"email": strings.ToLower(req.Email)}).Decode(&user)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	}
 	if err != nil {
+		// Unknown emails count too, so the counters don't reveal which accounts exist.
+		ac.recordLoginFailure(cctx, accountKey, ipKey)
 		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Login failed. Please check your credentials and try again.", nil)
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Login failed. Please check your credentials and try again.", nil)
+	if err := ac.Throttler.Reset(cctx, accountKey); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
 		return
 	}
 
-	token, err := utils.CreateToken(user.ID, ac.TokenTTLHours)
+	ac.completeLogin(ctx, cctx, user, "Login successful!")
+}
+
+// createAccount inserts user as the owner of a fresh workspace. Other
+// members are added to it through /admin/users. user.ID and
+// user.WorkspaceID are filled in on success.
+func (ac *AuthController) createAccount(cctx context.Context, user *models.User, workspaceName string) error {
+	now := time.Now().UTC()
+	user.ID = primitive.NewObjectID()
+
+	workspaceName = strings.TrimSpace(workspaceName)
+	if workspaceName == "" {
+		workspaceName = user.FirstName + "'s workspace"
+	}
+	workspace := models.Workspace{
+		ID:        primitive.NewObjectID(),
+		Name:      workspaceName,
+		OwnerID:   user.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if _, err := ac.WorkspaceColl.InsertOne(cctx, workspace); err != nil {
+		return err
+	}
+
+	user.WorkspaceID = workspace.ID
+	user.Role = models.RoleOwner
+	user.CreatedBy = user.ID
+	user.UpdatedBy = user.ID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	if _, err := ac.UserColl.InsertOne(cctx, user); err != nil {
+		_, _ = ac.WorkspaceColl.DeleteOne(cctx, bson.M{"_id": workspace.ID})
+		return err
+	}
+	return nil
+}
+
+// completeLogin finishes a first-factor login: users with TOTP get a
+// challenge token, everyone else gets a token pair.
+func (ac *AuthController) completeLogin(ctx iris.Context, cctx context.Context, user models.User, message string) {
+	if user.TOTPEnabled {
+		challenge, err := utils.CreateChallengeToken(user.ID, 5*time.Minute)
+		if err != nil {
+			utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to create token", nil)
+			return
+		}
+		utils.SendResponse(ctx, http.StatusOK, "success", "Two-factor code required", iris.Map{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
+	}
+
+	ac.sendLoginSuccess(ctx, cctx, user, message)
+}
+
+func (ac *AuthController) recordLoginFailure(cctx context.Context, accountKey, ipKey string) {
+	if err := ac.Throttler.Fail(cctx, accountKey, ac.Throttler.AccountPolicy); err != nil {
+		log.Println("login throttle update failed:", err)
+	}
+	if err := ac.Throttler.Fail(cctx, ipKey, ac.Throttler.IPPolicy); err != nil {
+		log.Println("login throttle update failed:", err)
+	}
+}
+
+// sendLoginSuccess issues a token pair for user and writes the login payload
+// shared by Signup, Login and VerifyTwoFactor.
+func (ac *AuthController) sendLoginSuccess(ctx iris.Context, cctx context.Context, user models.User, message string) {
+	token, refreshToken, err := ac.issueTokenPair(ctx, cctx, user, models.RefreshToken{})
 	if err != nil {
 		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to create token", nil)
 		return
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Login successful!", iris.Map{
-		"token": token,
+	utils.SendResponse(ctx, http.StatusOK, "success", message, iris.Map{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(ac.AccessTokenTTL.Seconds()),
 		"user": iris.Map{
-			"id":         user.ID.Hex(),
-			"first_name": user.FirstName,
-			"last_name":  user.LastName,
-			"email":      user.Email,
-			"phone":      user.Phone,
+			"id":             user.ID.Hex(),
+			"first_name":     user.FirstName,
+			"last_name":      user.LastName,
+			"email":          user.Email,
+			"email_verified": user.EmailVerified,
+			"phone":          user.Phone,
 			"dob": func() string {
 				if user.DOB.IsZero() {
 					return ""
 				}
 				return user.DOB.Format("2006-01-02")
 			}(),
-			"gender": user.Gender,
+			"gender":       user.Gender,
+			"role":         user.Role,
+			"workspace_id": user.WorkspaceID.Hex(),
+			"totp_enabled": user.TOTPEnabled,
 		},
 	})
 }
 
-// Iris middleware: requires Authorization: Bearer <token>
-// Usage: app.UseRouter(authMiddleware) or route.Use(authMiddleware)
-func AuthMiddleware(userColl *mongo.Collection) iris.Handler {
-	return func(ctx iris.Context) {
-		auth := ctx.GetHeader("Authorization")
-		if auth == "" {
-			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "missing authorization header", nil)
+// Refresh → POST /auth/refresh
+// Rotates the presented refresh token. Presenting a token that was already
+// rotated is treated as theft and revokes the whole token family.
+func (ac *AuthController) Refresh(ctx iris.Context) {
+	var req RefreshRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.RefreshToken == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "refresh_token required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	hash := utils.HashToken(req.RefreshToken)
+	nextID := primitive.NewObjectID()
+
+	var current models.RefreshToken
+	err := ac.RefreshColl.FindOneAndUpdate(cctx,
+		bson.M{"token_hash": hash, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now, "replaced_by": nextID}},
+	).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		var used models.RefreshToken
+		if err := ac.RefreshColl.FindOne(cctx, bson.M{"token_hash": hash}).Decode(&used); err == nil {
+			// Reuse of a rotated token: end the whole session so the thief's copy dies too.
+			_ = ac.revokeSession(cctx, used.FamilyID)
+		}
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	if now.After(current.ExpiresAt) {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+		return
+	}
+
+	// Re-read the user so role changes take effect on the next refresh.
+	var user models.User
+	if err := ac.UserColl.FindOne(cctx, bson.M{"_id": current.UserID}).Decode(&user); err != nil {
+		_ = ac.revokeSession(cctx, current.FamilyID)
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+		return
+	}
+
+	token, refreshToken, err := ac.issueTokenPair(ctx, cctx, user, models.RefreshToken{
+		ID:       nextID,
+		FamilyID: current.FamilyID,
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to create token", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Token refreshed!", iris.Map{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(ac.AccessTokenTTL.Seconds()),
+	})
+}
+
+// Logout → POST /auth/logout
+// Ends the session the refresh token belongs to and, when a bearer token is
+// sent along, blocks that access token for the rest of its lifetime.
+func (ac *AuthController) Logout(ctx iris.Context) {
+	var req RefreshRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.RefreshToken == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "refresh_token required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	var current models.RefreshToken
+	err := ac.RefreshColl.FindOne(cctx, bson.M{"token_hash": utils.HashToken(req.RefreshToken)}).Decode(&current)
+	if err != nil && err != mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if err == nil {
+		if err := ac.revokeSession(cctx, current.FamilyID); err != nil {
+			utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
 			return
 		}
-		parts := strings.SplitN(auth, " ", 2)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+	}
+
+	if claims, err := utils.ValidateToken(bearerToken(ctx)); err == nil {
+		if err := revokeAccessToken(cctx, ac.RevokedColl, claims); err != nil {
+			utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
 			return
 		}
-		tokenStr := parts[1]
-		userID, err := utils.ValidateToken(tokenStr)
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Logged out successfully!", nil)
+}
+
+// issueTokenPair signs an access token for user and persists a fresh refresh
+// token. When rotating, rt carries the family and pre-allocated ID; otherwise
+// a new session is recorded for the requesting device.
+func (ac *AuthController) issueTokenPair(ctx iris.Context, cctx context.Context, user models.User, rt models.RefreshToken) (string, string, error) {
+	now := time.Now().UTC()
+	rt.UserID = user.ID
+	if rt.ID.IsZero() {
+		rt.ID = primitive.NewObjectID()
+	}
+	rt.ExpiresAt = now.Add(ac.RefreshTokenTTL)
+	rt.CreatedAt = now
+
+	if rt.FamilyID.IsZero() {
+		rt.FamilyID = rt.ID
+		_, err := ac.SessionColl.InsertOne(cctx, models.Session{
+			ID:         rt.FamilyID,
+			UserID:     user.ID,
+			UserAgent:  ctx.GetHeader("User-Agent"),
+			IP:         ctx.RemoteAddr(),
+			CreatedAt:  now,
+			LastSeenAt: now,
+			ExpiresAt:  rt.ExpiresAt,
+		})
+		if err != nil {
+			return "", "", err
+		}
+	} else {
+		_, err := ac.SessionColl.UpdateByID(cctx, rt.FamilyID, bson.M{"$set": bson.M{
+			"ip":           ctx.RemoteAddr(),
+			"user_agent":   ctx.GetHeader("User-Agent"),
+			"last_seen_at": now,
+			"expires_at":   rt.ExpiresAt,
+		}})
 		if err != nil {
-			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "invalid token", nil)
-			return
+			return "", "", err
 		}
-		ctx.Values().Set("userID", userID)
-		ctx.Next()
 	}
-}
\ No newline at end of file
+
+	token, err := utils.CreateToken(utils.TokenClaims{
+		UserID:        user.ID,
+		WorkspaceID:   user.WorkspaceID,
+		SessionID:     rt.FamilyID,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+	}, ac.AccessTokenTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	rt.TokenHash = utils.HashToken(refreshToken)
+
+	if _, err := ac.RefreshColl.InsertOne(cctx, rt); err != nil {
+		return "", "", err
+	}
+	return token, refreshToken, nil
+}
+
+// sessionRevocationKey is the revoked-tokens entry that blocks every access
+// token carrying sid, see AuthMiddleware.
+func sessionRevocationKey(sessionID primitive.ObjectID) string {
+	return "session:" + sessionID.Hex()
+}
+
+func revokeAccessToken(cctx context.Context, revokedColl *mongo.Collection, claims *utils.TokenClaims) error {
+	_, err := revokedColl.UpdateByID(cctx, claims.JTI, bson.M{
+		"$setOnInsert": models.RevokedToken{
+			JTI:       claims.JTI,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt,
+			RevokedAt: time.Now().UTC(),
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// ResendVerification → POST /auth/resend-verification
+func (ac *AuthController) ResendVerification(ctx iris.Context) {
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if user.EmailVerified {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "Email is already verified", nil)
+		return
+	}
+
+	if err := ac.sendVerificationMail(user); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to send verification email", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Verification email sent!", nil)
+}
+
+// VerifyEmail → POST /auth/verify-email
+// The frontend posts the token from the emailed link here. The new state
+// shows up in the next access token, so clients should refresh afterwards.
+func (ac *AuthController) VerifyEmail(ctx iris.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Token == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "token required", nil)
+		return
+	}
+
+	userID, email, err := utils.ValidateEmailToken(req.Token)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Verification link is invalid or has expired", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	res, err := ac.UserColl.UpdateOne(cctx,
+		bson.M{"_id": userID, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if res.MatchedCount == 0 {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Verification link is invalid or has expired", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Email verified successfully!", nil)
+}
+
+func (ac *AuthController) sendVerificationMail(user models.User) error {
+	token, err := utils.CreateEmailToken(user.ID, user.Email, ac.VerifyTokenTTL)
+	if err != nil {
+		return err
+	}
+	return ac.Mailer.Send(utils.Mail{
+		To:      user.Email,
+		Subject: "Verify your Pai Finance email",
+		Body: "Hi " + user.FirstName + ",\n\n" +
+			"Please confirm this is your email address by opening the link below. It expires in " + ac.VerifyTokenTTL.String() + ".\n\n" +
+			utils.AppURL() + "/verify-email?token=" + token + "\n",
+	})
+}
+
+// sendVerificationMailAsync is used right after sign-up, where a mail
+// failure shouldn't fail the request; the user can ask for a resend.
+func (ac *AuthController) sendVerificationMailAsync(user models.User) {
+	go func() {
+		if err := ac.sendVerificationMail(user); err != nil {
+			log.Println("verification mail failed:", err)
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportTimeout bounds how long an export may stream for.
+const exportTimeout = 2 * time.Minute
+
+// exportFlushEvery is how many CSV rows are written between flushes.
+const exportFlushEvery = 500
+
+var exportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":  "application/pdf",
+}
+
+// startExport checks ?format (csv, xlsx or pdf; default csv) and sets the
+// download headers. It writes a 400 and returns false for other formats.
+// Nothing else may be written as JSON once it succeeds.
+func startExport(ctx iris.Context, name string) (string, bool) {
+	format := strings.ToLower(ctx.URLParamDefault("format", "csv"))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "format must be csv, xlsx or pdf", nil)
+		return "", false
+	}
+
+	filename := name + "-" + utils.Today().Format("2006-01-02") + "." + format
+	ctx.ContentType(contentType)
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Header("Cache-Control", "no-store")
+	return format, true
+}
+
+// exportContext is the context an export streams under. It ends with the
+// request, so a cancelled download stops reading the cursor.
+func exportContext(ctx iris.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx.Request().Context(), exportTimeout)
+}
+
+// exportPeriod describes the from/to query parameters for report headers.
+func exportPeriod(ctx iris.Context) string {
+	label := func(v string) string {
+		if d, err := utils.ParseDay(v); err == nil {
+			return d.Format("02 Jan 2006")
+		}
+		return v
+	}
+	from, to := ctx.URLParam("from"), ctx.URLParam("to")
+	switch {
+	case from != "" && to != "":
+		return "Period: " + label(from) + " to " + label(to)
+	case from != "":
+		return "Period: from " + label(from)
+	case to != "":
+		return "Period: up to " + label(to)
+	default:
+		return "Period: all dates"
+	}
+}
+
+// xlsxStyles are the cell styles exports share.
+type xlsxStyles struct {
+	header, date, inr, integer int
+}
+
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	var s xlsxStyles
+	var err error
+	if s.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"E6E6E6"}},
+	}); err != nil {
+		return s, err
+	}
+	dateFormat := "dd-mmm-yyyy"
+	if s.date, err = f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return s, err
+	}
+	inrFormat := utils.XLSXINRFormat
+	if s.inr, err = f.NewStyle(&excelize.Style{CustomNumFmt: &inrFormat}); err != nil {
+		return s, err
+	}
+	s.integer, err = f.NewStyle(&excelize.Style{NumFmt: 3})
+	return s, err
+}
+
+// headerRow styles a row of column titles.
+func headerRow(style int, titles ...string) []interface{} {
+	row := make([]interface{}, len(titles))
+	for i, t := range titles {
+		row[i] = excelize.Cell{StyleID: style, Value: t}
+	}
+	return row
+}
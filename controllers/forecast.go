@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ForecastController struct {
+	SalesColl   *mongo.Collection
+	StoreColl   *mongo.Collection
+	ProductColl *mongo.Collection
+	HolidayColl *mongo.Collection
+}
+
+func NewForecastController(salesColl, storeColl, productColl, holidayColl *mongo.Collection) *ForecastController {
+	return &ForecastController{
+		SalesColl:   salesColl,
+		StoreColl:   storeColl,
+		ProductColl: productColl,
+		HolidayColl: holidayColl,
+	}
+}
+
+// forecastParams are the knobs a forecast is run with.
+type forecastParams struct {
+	Method      string  `json:"method"`
+	Alpha       float64 `json:"alpha,omitempty"`
+	Window      int     `json:"window,omitempty"`
+	History     int     `json:"history"`
+	LeadTime    int     `json:"lead_time"`
+	SafetyStock int     `json:"safety_stock"`
+	CoverDays   int     `json:"cover_days"`
+}
+
+// GetStockForecast → GET /forecast
+// Projects each store's stock, and each product's in stores that track
+// them, from the logs of the last history days (default 90). Velocity is
+// estimated with method=ses (alpha, default 0.3) or method=moving_average
+// (window, default 14 logged days). An order is suggested once stock falls
+// to the sales expected over lead_time days (default 7) plus safety_stock
+// units, sized to last cover_days (default 14) beyond the lead time.
+// Velocity is per logged day, so dates are projected over trading days,
+// skipping the workspace's holidays, from today: a store whose latest log is
+// older is assumed to have kept selling since, and is flagged stale once
+// that log is older than the lead time. Forecasts every store unless
+// narrowed with store_id.
+func (fc *ForecastController) GetStockForecast(ctx iris.Context) {
+	p := forecastParams{
+		Method:      ctx.URLParamDefault("method", utils.ForecastSES),
+		History:     ctx.URLParamIntDefault("history", 90),
+		LeadTime:    ctx.URLParamIntDefault("lead_time", 7),
+		SafetyStock: ctx.URLParamIntDefault("safety_stock", 0),
+		CoverDays:   ctx.URLParamIntDefault("cover_days", 14),
+	}
+	switch p.Method {
+	case utils.ForecastSES:
+		p.Alpha = ctx.URLParamFloat64Default("alpha", 0.3)
+		if p.Alpha <= 0 || p.Alpha > 1 {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "alpha must be greater than 0 and at most 1", nil)
+			return
+		}
+	case utils.ForecastMovingAverage:
+		p.Window = ctx.URLParamIntDefault("window", 14)
+		if p.Window < 1 || p.Window > 366 {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "window must be between 1 and 366 days", nil)
+			return
+		}
+	default:
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "method must be "+utils.ForecastSES+" or "+utils.ForecastMovingAverage, nil)
+		return
+	}
+	if p.History < 7 || p.History > 3*366 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "history must be between 7 and 1098 days", nil)
+		return
+	}
+	if p.LeadTime < 0 || p.LeadTime > 366 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "lead_time must be between 0 and 366 days", nil)
+		return
+	}
+	if p.SafetyStock < 0 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "safety_stock can't be negative", nil)
+		return
+	}
+	if p.CoverDays < 1 || p.CoverDays > 366 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "cover_days must be between 1 and 366 days", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	today := utils.Today()
+	filter := bson.M{"date": bson.M{"$gte": today.AddDate(0, 0, -p.History), "$lt": today.AddDate(0, 0, 1)}}
+	if v := ctx.URLParam("store_id"); v != "" {
+		store, err := findStore(timeoutCtx, fc.StoreColl, workspaceID(ctx), v)
+		if errors.Is(err, errUnknownStore) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+			return
+		}
+		filter["store_id"] = store.ID
+	}
+
+	cursor, err := fc.SalesColl.Find(timeoutCtx, scoped(ctx, filter),
+		options.Find().SetSort(bson.D{{Key: "store_id", Value: 1}, {Key: "date", Value: 1}}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch sales-logs: "+err.Error(), nil)
+		return
+	}
+	var logs []models.SalesLog
+	if err := cursor.All(timeoutCtx, &logs); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode sales-logs: "+err.Error(), nil)
+		return
+	}
+
+	stores, err := storeNames(timeoutCtx, fc.StoreColl, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch stores: "+err.Error(), nil)
+		return
+	}
+	products, err := fc.productNames(timeoutCtx, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch products: "+err.Error(), nil)
+		return
+	}
+	cal, err := loadHolidayCalendar(timeoutCtx, fc.HolidayColl, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch holidays: "+err.Error(), nil)
+		return
+	}
+
+	forecasts := []models.StockForecast{}
+	for start := 0; start < len(logs); {
+		end := start
+		for end < len(logs) && logs[end].StoreID == logs[start].StoreID {
+			end++
+		}
+		forecasts = append(forecasts, forecastStore(logs[start:end], stores[logs[start].StoreID], products, cal, today, p)...)
+		start = end
+	}
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		return forecasts[i].StoreName < forecasts[j].StoreName
+	})
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Stock forecast generated successfully!", iris.Map{
+		"params":    p,
+		"forecasts": forecasts,
+	})
+}
+
+// forecastStore forecasts one store from its logs, oldest first: the store
+// as a whole, then each product its latest log tracks, by SKU.
+func forecastStore(logs []models.SalesLog, storeName string, products map[primitive.ObjectID]string, cal holidayCalendar, today time.Time, p forecastParams) []models.StockForecast {
+	last := logs[len(logs)-1]
+
+	sales := make([]float64, len(logs))
+	for i, l := range logs {
+		sales[i] = float64(l.Sales)
+	}
+	total := project(sales, last.Physical, last.Date, today, cal, p)
+	total.StoreID, total.StoreName = last.StoreID, storeName
+	forecasts := []models.StockForecast{total}
+
+	// A product's series starts at the first log it appears in; logs after
+	// that which leave it out sold none of it.
+	byProduct := make([]map[primitive.ObjectID]models.SalesLogLine, len(logs))
+	for i, l := range logs {
+		byProduct[i] = linesByProduct(l)
+	}
+	var lines []models.StockForecast
+	for _, line := range last.Lines {
+		var series []float64
+		seen := false
+		for i := range logs {
+			if sold, ok := byProduct[i][line.ProductID]; ok {
+				seen = true
+				series = append(series, float64(sold.Sales))
+			} else if seen {
+				series = append(series, 0)
+			}
+		}
+		f := project(series, line.Physical, last.Date, today, cal, p)
+		productID := line.ProductID
+		f.StoreID, f.StoreName = last.StoreID, storeName
+		f.ProductID, f.SKU, f.ProductName = &productID, line.SKU, products[line.ProductID]
+		lines = append(lines, f)
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].SKU < lines[j].SKU })
+	return append(forecasts, lines...)
+}
+
+// project estimates velocity from sales, takes stock counted on asOf down by
+// the trading days since, and runs it forward from today over the days cal
+// has the store open. Lead time and cover are calendar days, so only the
+// trading days within them consume stock.
+func project(sales []float64, stock int, asOf, today time.Time, cal holidayCalendar, p forecastParams) models.StockForecast {
+	var velocity float64
+	if p.Method == utils.ForecastMovingAverage {
+		velocity = utils.MovingAverage(sales, p.Window)
+	} else {
+		velocity = utils.SmoothExponential(sales, p.Alpha)
+	}
+	velocity = math.Max(velocity, 0)
+
+	elapsed := int(today.Sub(asOf).Hours() / 24)
+	estimated := stock
+	if elapsed > 0 {
+		sold := int(math.Round(velocity * float64(tradingDaysWithin(asOf, elapsed, cal))))
+		estimated = max(stock-sold, 0)
+	}
+
+	f := models.StockForecast{
+		AsOf:           asOf,
+		Stock:          stock,
+		EstimatedStock: estimated,
+		Stale:          elapsed > p.LeadTime,
+		DaysObserved:   len(sales),
+		Velocity:       utils.RoundTo(velocity, 2),
+	}
+	stock = estimated
+
+	leadDays := tradingDaysWithin(today, p.LeadTime, cal)
+	coverDays := tradingDaysWithin(today, p.LeadTime+p.CoverDays, cal)
+	reorderPoint := int(math.Ceil(velocity*float64(leadDays))) + p.SafetyStock
+	f.Reorder = models.ReorderSuggestion{
+		ReorderPoint: reorderPoint,
+		OrderUpTo:    int(math.Ceil(velocity*float64(coverDays))) + p.SafetyStock,
+	}
+	f.Reorder.ReorderNow = stock <= reorderPoint && f.Reorder.OrderUpTo > stock
+	if f.Reorder.ReorderNow {
+		f.Reorder.Quantity = f.Reorder.OrderUpTo - stock
+		f.Reorder.ReorderBy = &today
+	}
+
+	if velocity > 0 {
+		cover := math.Max(float64(stock)/velocity, 0)
+		days := utils.RoundTo(cover, 1)
+		f.DaysOfCover = &days
+
+		// Stock runs out during the trading day its cover ends in.
+		if out, ok := addTradingDays(today, math.Ceil(cover), cal); ok {
+			f.StockOutDate = &out
+		}
+
+		if f.Reorder.ReorderBy == nil && stock > reorderPoint {
+			if by, ok := addTradingDays(today, math.Floor(float64(stock-reorderPoint)/velocity), cal); ok {
+				f.Reorder.ReorderBy = &by
+			}
+		}
+	}
+	return f
+}
+
+// addTradingDays returns the nth trading day after from. It gives up, with
+// ok false, past maxGapRange calendar days, which is also where a calendar
+// closed every day of the week ends up.
+func addTradingDays(from time.Time, n float64, cal holidayCalendar) (time.Time, bool) {
+	day := from
+	for i := 0; n > 0; i++ {
+		if i >= maxGapRange {
+			return time.Time{}, false
+		}
+		day = day.AddDate(0, 0, 1)
+		if !cal.closed(day) {
+			n--
+		}
+	}
+	return day, true
+}
+
+// tradingDaysWithin counts the trading days in the calendar days after from.
+func tradingDaysWithin(from time.Time, days int, cal holidayCalendar) int {
+	n := 0
+	for i := 1; i <= days; i++ {
+		if !cal.closed(from.AddDate(0, 0, i)) {
+			n++
+		}
+	}
+	return n
+}
+
+// productNames maps the workspace's product IDs to their names.
+func (fc *ForecastController) productNames(cctx context.Context, workspace primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	cursor, err := fc.ProductColl.Find(cctx, bson.M{"workspace_id": workspace}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var products []models.Product
+	if err := cursor.All(cctx, &products); err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(products))
+	for _, p := range products {
+		names[p.ID] = p.Name
+	}
+	return names, nil
+}
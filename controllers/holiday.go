@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type HolidayController struct {
+	HolidayColl *mongo.Collection
+}
+
+func NewHolidayController(holidayColl *mongo.Collection) *HolidayController {
+	return &HolidayController{
+		HolidayColl: holidayColl,
+	}
+}
+
+// GetHolidays → GET /holidays
+func (hc *HolidayController) GetHolidays(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := hc.HolidayColl.Find(timeoutCtx, scoped(ctx, bson.M{}),
+		options.Find().SetSort(bson.D{{Key: "weekday", Value: 1}, {Key: "date", Value: 1}}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch holidays: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	holidays := []models.Holiday{}
+	if err := cursor.All(timeoutCtx, &holidays); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode holidays: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Holidays fetched successfully!", holidays)
+}
+
+// AddHoliday → POST /holidays
+func (hc *HolidayController) AddHoliday(ctx iris.Context) {
+	var req models.HolidayRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	holiday := models.Holiday{
+		WorkspaceID: workspaceID(ctx),
+		Name:        strings.TrimSpace(req.Name),
+		CreatedBy:   currentUserID(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	switch {
+	case req.Date != "" && req.Weekday != nil:
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Give either date or weekday, not both", nil)
+		return
+	case req.Date != "":
+		day, err := utils.ParseDay(req.Date)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "date must be in YYYY-MM-DD format", nil)
+			return
+		}
+		holiday.Date = &day
+	case req.Weekday != nil:
+		if *req.Weekday < time.Sunday || *req.Weekday > time.Saturday {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "weekday must be 0 (Sunday) to 6 (Saturday)", nil)
+			return
+		}
+		holiday.Weekday = req.Weekday
+	default:
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "date or weekday is required", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := hc.HolidayColl.InsertOne(timeoutCtx, holiday)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert holiday: "+err.Error(), nil)
+		return
+	}
+	holiday.ID = result.InsertedID.(primitive.ObjectID)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Holiday created successfully!", holiday)
+}
+
+// DeleteHolidayByID → DELETE /holidays/{id}
+func (hc *HolidayController) DeleteHolidayByID(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := hc.HolidayColl.DeleteOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	if res.DeletedCount == 0 {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Holiday not found", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Holiday deleted successfully!", iris.Map{"id": objectID})
+}
+
+// holidayCalendar answers whether the shop is closed on a given day.
+type holidayCalendar struct {
+	dates    map[string]bool
+	weekdays map[time.Weekday]bool
+}
+
+func loadHolidayCalendar(cctx context.Context, holidayColl *mongo.Collection, workspace primitive.ObjectID) (holidayCalendar, error) {
+	cal := holidayCalendar{dates: map[string]bool{}, weekdays: map[time.Weekday]bool{}}
+
+	cursor, err := holidayColl.Find(cctx, bson.M{"workspace_id": workspace})
+	if err != nil {
+		return cal, err
+	}
+	var holidays []models.Holiday
+	if err := cursor.All(cctx, &holidays); err != nil {
+		return cal, err
+	}
+
+	for _, h := range holidays {
+		if h.Weekday != nil {
+			cal.weekdays[*h.Weekday] = true
+		}
+		if h.Date != nil {
+			cal.dates[utils.DayKey(*h.Date)] = true
+		}
+	}
+	return cal, nil
+}
+
+func (cal holidayCalendar) closed(day time.Time) bool {
+	return cal.weekdays[day.Weekday()] || cal.dates[utils.DayKey(day)]
+}
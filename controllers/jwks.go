@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+)
+
+// GetJWKS → GET /.well-known/jwks.json
+// Public keys for verifying pai-finance tokens. This is served bare, not in
+// the APIResponse envelope, because JWKS clients expect the standard shape.
+func GetJWKS(ctx iris.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(iris.Map{"keys": utils.JWKS()})
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Iris middleware: requires Authorization: Bearer <token>, where the token is
+// either a JWT or a personal API key. API keys may also be sent as X-API-Key.
+// Usage: app.UseRouter(authMiddleware) or route.Use(authMiddleware)
+func AuthMiddleware(revokedColl, apiKeyColl, userColl *mongo.Collection) iris.Handler {
+	return func(ctx iris.Context) {
+		auth := ctx.GetHeader("Authorization")
+		apiKey := ctx.GetHeader("X-API-Key")
+		if auth == "" && apiKey == "" {
+			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "missing authorization header", nil)
+			return
+		}
+
+		cctx, cancel := context.WithTimeout(ctx.Request().Context(), 5*time.Second)
+		defer cancel()
+
+		if apiKey != "" {
+			authenticateAPIKey(ctx, cctx, apiKeyColl, userColl, apiKey)
+			return
+		}
+
+		parts := strings.SplitN(auth, " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+			return
+		}
+		tokenStr := parts[1]
+		if utils.IsAPIKey(tokenStr) {
+			authenticateAPIKey(ctx, cctx, apiKeyColl, userColl, tokenStr)
+			return
+		}
+
+		claims, err := utils.ValidateToken(tokenStr)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "invalid token", nil)
+			return
+		}
+
+		revoked, err := revokedColl.CountDocuments(cctx, bson.M{"_id": bson.M{"$in": bson.A{
+			claims.JTI,
+			sessionRevocationKey(claims.SessionID),
+		}}})
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "database error", nil)
+			return
+		}
+		if revoked > 0 {
+			utils.SendResponse(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+			return
+		}
+
+		ctx.Values().Set("userID", claims.UserID)
+		ctx.Values().Set("workspaceID", claims.WorkspaceID)
+		ctx.Values().Set("role", claims.Role)
+		ctx.Values().Set("emailVerified", claims.EmailVerified)
+		ctx.Values().Set("jti", claims.JTI)
+		ctx.Values().Set("sessionID", claims.SessionID)
+		ctx.Next()
+	}
+}
+
+// authenticateAPIKey resolves a personal API key to its owner. The owner's
+// current role applies, narrowed by the key's scopes.
+func authenticateAPIKey(ctx iris.Context, cctx context.Context, apiKeyColl, userColl *mongo.Collection, key string) {
+	var apiKey models.APIKey
+	err := apiKeyColl.FindOne(cctx, bson.M{
+		"key_hash":   utils.HashToken(key),
+		"revoked_at": bson.M{"$exists": false},
+	}).Decode(&apiKey)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusUnauthorized, "error", "invalid API key", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	var user models.User
+	err = userColl.FindOne(cctx, bson.M{"_id": apiKey.UserID, "workspace_id": apiKey.WorkspaceID}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusUnauthorized, "error", "invalid API key", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	if models.APIKeyReadOnly(apiKey.Scopes) && ctx.Method() != http.MethodGet && ctx.Method() != http.MethodHead {
+		utils.SendResponse(ctx, http.StatusForbidden, "error", "This API key is read-only", nil)
+		return
+	}
+
+	// Only write last_used_at about once a minute to keep busy scripts cheap.
+	now := time.Now().UTC()
+	_, err = apiKeyColl.UpdateOne(cctx,
+		bson.M{"_id": apiKey.ID, "$or": []bson.M{
+			{"last_used_at": bson.M{"$exists": false}},
+			{"last_used_at": bson.M{"$lt": now.Add(-time.Minute)}},
+		}},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	ctx.Values().Set("userID", user.ID)
+	ctx.Values().Set("workspaceID", user.WorkspaceID)
+	ctx.Values().Set("role", user.Role)
+	ctx.Values().Set("emailVerified", user.EmailVerified)
+	ctx.Values().Set("apiKeyID", apiKey.ID)
+	ctx.Values().Set("apiKeyScopes", apiKey.Scopes)
+	ctx.Next()
+}
+
+// RequirePermission rejects requests whose role lacks perm with a 403.
+// Register it after AuthMiddleware on a Party, e.g.
+// router.Party("/admin", authMiddleware, RequirePermission(models.PermUsers))
+func RequirePermission(perm string) iris.Handler {
+	return func(ctx iris.Context) {
+		if !models.HasPermission(ctx.Values().GetString("role"), perm) {
+			utils.SendResponseAndStop(ctx, http.StatusForbidden, "error", "You do not have access to this resource", nil)
+			return
+		}
+		if !apiKeyAllows(ctx, perm) {
+			utils.SendResponseAndStop(ctx, http.StatusForbidden, "error", "This API key is not allowed to access this resource", nil)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// hasPermission reports whether the caller passes RequirePermission(perm),
+// for handlers that only gate part of what they do.
+func hasPermission(ctx iris.Context, perm string) bool {
+	return models.HasPermission(ctx.Values().GetString("role"), perm) && apiKeyAllows(ctx, perm)
+}
+
+// apiKeyAllows reports whether the caller's API key, if they used one, is
+// scoped to perm.
+func apiKeyAllows(ctx iris.Context, perm string) bool {
+	scopes, ok := ctx.Values().Get("apiKeyScopes").([]string)
+	return !ok || models.APIKeyAllows(scopes, perm)
+}
+
+// RequireVerifiedEmail blocks accounts that haven't verified their email.
+// It is a no-op unless REQUIRE_VERIFIED_EMAIL=true, so existing deployments
+// can turn it on once users have had a chance to verify.
+func RequireVerifiedEmail() iris.Handler {
+	enabled := os.Getenv("REQUIRE_VERIFIED_EMAIL") == "true"
+	return func(ctx iris.Context) {
+		if enabled && !ctx.Values().GetBoolDefault("emailVerified", false) {
+			utils.SendResponseAndStop(ctx, http.StatusForbidden, "error", "Please verify your email address first", nil)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// DenyAPIKeys keeps account-security endpoints (2FA, API key management)
+// reachable only from an interactive login.
+func DenyAPIKeys(ctx iris.Context) {
+	if _, ok := ctx.Values().Get("apiKeyID").(primitive.ObjectID); ok {
+		utils.SendResponseAndStop(ctx, http.StatusForbidden, "error", "API keys cannot be used for this endpoint", nil)
+		return
+	}
+	ctx.Next()
+}
+
+// currentUserID returns the user AuthMiddleware authenticated.
+func currentUserID(ctx iris.Context) primitive.ObjectID {
+	id, _ := ctx.Values().Get("userID").(primitive.ObjectID)
+	return id
+}
+
+// currentSessionID returns the session of a JWT-authenticated request, or
+// NilObjectID for API keys.
+func currentSessionID(ctx iris.Context) primitive.ObjectID {
+	id, _ := ctx.Values().Get("sessionID").(primitive.ObjectID)
+	return id
+}
+
+// bearerToken extracts the token from "Authorization: Bearer <token>", or "".
+func bearerToken(ctx iris.Context) string {
+	parts := strings.SplitN(ctx.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OIDCController signs users in through OpenID Connect providers using the
+// authorization-code flow with PKCE, then hands off to AuthController so the
+// result is an ordinary pai-finance token pair.
+type OIDCController struct {
+	Auth      *AuthController
+	StateColl *mongo.Collection
+	Providers map[string]*utils.OIDCProvider
+	StateTTL  time.Duration
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+func NewOIDCController(auth *AuthController, stateColl *mongo.Collection, providers map[string]*utils.OIDCProvider) *OIDCController {
+	return &OIDCController{
+		Auth:      auth,
+		StateColl: stateColl,
+		Providers: providers,
+		StateTTL:  10 * time.Minute,
+	}
+}
+
+// GetOIDCProviders → GET /auth/oidc
+func (oc *OIDCController) GetOIDCProviders(ctx iris.Context) {
+	names := make([]string, 0, len(oc.Providers))
+	for name := range oc.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Sign-in providers fetched successfully!", names)
+}
+
+// StartOIDCLogin → GET /auth/oidc/{provider}
+// Returns the provider's authorization URL, or redirects to it with
+// ?redirect=true. The provider sends the browser back to the configured
+// redirect URL with code and state, which the frontend posts to the callback.
+func (oc *OIDCController) StartOIDCLogin(ctx iris.Context) {
+	provider, ok := oc.Providers[strings.ToLower(ctx.Params().Get("provider"))]
+	if !ok {
+		utils.SendResponseAndStop(ctx, http.StatusNotFound, "error", "Unknown sign-in provider", nil)
+		return
+	}
+
+	state, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate state", nil)
+		return
+	}
+	nonce, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate nonce", nil)
+		return
+	}
+	verifier, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate code verifier", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 10*time.Second)
+	defer cancel()
+
+	authURL, err := provider.AuthorizationURL(cctx, state, nonce, verifier)
+	if err != nil {
+		log.Println("oidc:", err)
+		utils.SendResponseAndStop(ctx, http.StatusBadGateway, "error", "Sign-in provider is unavailable", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	_, err = oc.StateColl.InsertOne(cctx, models.OIDCState{
+		ID:           utils.HashToken(state),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(oc.StateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	if ctx.URLParamBoolDefault("redirect", false) {
+		ctx.Redirect(authURL, http.StatusFound)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Continue sign-in with "+provider.Name, iris.Map{
+		"authorization_url": authURL,
+		"state":             state,
+	})
+}
+
+// FinishOIDCLogin → POST /auth/oidc/{provider}/callback
+// Redeems the code, then signs in the user linked to the provider account.
+// Unlinked accounts are matched to an existing user whose email is verified
+// on both sides, or a new user and workspace are created.
+func (oc *OIDCController) FinishOIDCLogin(ctx iris.Context) {
+	provider, ok := oc.Providers[strings.ToLower(ctx.Params().Get("provider"))]
+	if !ok {
+		utils.SendResponseAndStop(ctx, http.StatusNotFound, "error", "Unknown sign-in provider", nil)
+		return
+	}
+
+	var req OIDCCallbackRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Code == "" || req.State == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "code and state required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 15*time.Second)
+	defer cancel()
+
+	// Deleting on read makes each state single-use.
+	var state models.OIDCState
+	err := oc.StateColl.FindOneAndDelete(cctx, bson.M{
+		"_id":        utils.HashToken(req.State),
+		"provider":   provider.Name,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Sign-in expired or already used. Please try again.", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	identity, err := provider.Exchange(cctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Println("oidc:", err)
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Sign-in with "+provider.Name+" failed", nil)
+		return
+	}
+
+	user, status, msg := oc.resolveUser(cctx, provider.Name, identity)
+	if status != 0 {
+		utils.SendResponseAndStop(ctx, status, "error", msg, nil)
+		return
+	}
+
+	oc.Auth.completeLogin(ctx, cctx, user, "Login successful!")
+}
+
+// resolveUser finds or creates the user for a verified provider identity.
+// A non-zero status reports why it couldn't.
+func (oc *OIDCController) resolveUser(cctx context.Context, providerName string, identity *utils.OIDCIdentity) (models.User, int, string) {
+	var user models.User
+	err := oc.Auth.UserColl.FindOne(cctx, bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{"provider": providerName, "subject": identity.Subject}},
+	}).Decode(&user)
+	if err == nil {
+		return user, 0, ""
+	} else if err != mongo.ErrNoDocuments {
+		return user, http.StatusInternalServerError, "database error"
+	}
+
+	// Only an address the provider has verified may claim an account.
+	if identity.Email == "" || !identity.EmailVerified {
+		return user, http.StatusForbidden, "Your " + providerName + " account has no verified email address"
+	}
+	email := strings.ToLower(identity.Email)
+	now := time.Now().UTC()
+	link := models.Identity{
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    email,
+		LinkedAt: now,
+	}
+
+	// Emails were not always unique, so refuse rather than pick one of
+	// several accounts, possibly in another workspace.
+	var matches []models.User
+	cursor, err := oc.Auth.UserColl.Find(cctx, bson.M{"email": email}, options.Find().SetLimit(2))
+	if err == nil {
+		err = cursor.All(cctx, &matches)
+	}
+	if err != nil {
+		return user, http.StatusInternalServerError, "database error"
+	}
+	if len(matches) > 1 {
+		return user, http.StatusConflict, "More than one account uses this email. Sign in with your password instead."
+	}
+
+	if len(matches) == 1 {
+		user = matches[0]
+		// Anyone can sign up with an address they don't own, so only an
+		// account that proved it owns the email is linked. Otherwise its
+		// password may belong to someone else; resetting it both proves
+		// ownership and signs out every session.
+		if !user.EmailVerified {
+			return user, http.StatusConflict, "An account with this email exists but hasn't verified it. Verify your email or reset your password, then sign in with " + providerName + " again."
+		}
+		_, err = oc.Auth.UserColl.UpdateByID(cctx, user.ID, bson.M{
+			"$push": bson.M{"identities": link},
+			"$set":  bson.M{"updated_at": now},
+		})
+		if err != nil {
+			return user, http.StatusInternalServerError, "database error"
+		}
+		user.Identities = append(user.Identities, link)
+		return user, 0, ""
+	}
+
+	// No password is set, so the account can only sign in through the
+	// provider until the user resets one.
+	user = models.User{
+		FirstName:     identity.GivenName,
+		LastName:      identity.FamilyName,
+		Email:         email,
+		EmailVerified: true,
+		Identities:    []models.Identity{link},
+	}
+	if user.FirstName == "" {
+		user.FirstName, user.LastName, _ = strings.Cut(identity.Name, " ")
+	}
+	if user.FirstName == "" {
+		user.FirstName, _, _ = strings.Cut(email, "@")
+	}
+
+	if err := oc.Auth.createAccount(cctx, &user, ""); err != nil {
+		return user, http.StatusInternalServerError, "failed to create account"
+	}
+	return user, 0, ""
+}
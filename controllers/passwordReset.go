@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ForgotPassword → POST /auth/forgot-password
+// Always answers with the same message, before looking the email up, so
+// neither the response nor its timing reveals which emails are registered.
+// The reset link is created and mailed in the background.
+func (ac *AuthController) ForgotPassword(ctx iris.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Email == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "email required", nil)
+		return
+	}
+
+	go func(email string) {
+		if err := ac.sendPasswordReset(email); err != nil {
+			log.Println("password reset failed:", err)
+		}
+	}(strings.ToLower(req.Email))
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "If that email is registered, a reset link is on its way.", nil)
+}
+
+// sendPasswordReset mails a reset link to email if it belongs to a user.
+func (ac *AuthController) sendPasswordReset(email string) error {
+	cctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := ac.UserColl.FindOne(cctx, bson.M{"email": email}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	// Only the newest link works; earlier unused ones are retired.
+	_, err = ac.ResetColl.UpdateMany(cctx,
+		bson.M{"user_id": user.ID, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ac.ResetColl.InsertOne(cctx, models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ac.ResetTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ac.Mailer.Send(utils.Mail{
+		To:      user.Email,
+		Subject: "Reset your Pai Finance password",
+		Body: "Hi " + user.FirstName + ",\n\n" +
+			"Use the link below to choose a new password. It expires in " + ac.ResetTokenTTL.String() + " and can be used once.\n\n" +
+			utils.AppURL() + "/reset-password?token=" + token + "\n\n" +
+			"If you didn't ask for this, you can ignore this email.\n",
+	})
+}
+
+// ResetPassword → POST /auth/reset-password
+// Consumes a reset token, sets the new password, signs the user out of
+// every existing session and cancels any pending email change. The link
+// arrived by email, so the address counts as verified.
+func (ac *AuthController) ResetPassword(ctx iris.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	if req.Token == "" || req.Password == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "token and password are required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+
+	var reset models.PasswordReset
+	err := ac.ResetColl.FindOneAndUpdate(cctx,
+		bson.M{
+			"token_hash": utils.HashToken(req.Token),
+			"used_at":    bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"used_at": now}},
+	).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Reset link is invalid or has expired", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to hash password", nil)
+		return
+	}
+
+	res, err := ac.UserColl.UpdateByID(cctx, reset.UserID, bson.M{
+		"$set": bson.M{"password": string(hashed), "email_verified": true, "updated_at": now},
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if res.MatchedCount == 0 {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Reset link is invalid or has expired", nil)
+		return
+	}
+
+	if _, err := ac.revokeUserSessions(cctx, reset.UserID, primitive.NilObjectID); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	// An email change requested before the reset may have been by whoever
+	// the reset locks out.
+	if _, err := ac.EmailChangeColl.DeleteMany(cctx, bson.M{"user_id": reset.UserID}); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Password reset successful! Please log in.", nil)
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProductController struct {
+	ProductColl *mongo.Collection
+	SalesColl   *mongo.Collection
+	Audit       *Auditor
+}
+
+func NewProductController(productColl, salesColl *mongo.Collection, audit *Auditor) *ProductController {
+	return &ProductController{
+		ProductColl: productColl,
+		SalesColl:   salesColl,
+		Audit:       audit,
+	}
+}
+
+// GetProducts → GET /products
+// Optional filters: category, search (SKU or name).
+func (pc *ProductController) GetProducts(ctx iris.Context) {
+	filter := scoped(ctx, bson.M{})
+	if category := ctx.URLParam("category"); category != "" {
+		filter["category"] = category
+	}
+	if search := ctx.URLParam("search"); search != "" {
+		filter["$or"] = []bson.M{
+			{"sku": bson.M{"$regex": search, "$options": "i"}},
+			{"name": bson.M{"$regex": search, "$options": "i"}},
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := pc.ProductColl.Find(timeoutCtx, filter, options.Find().SetSort(bson.D{{Key: "sku", Value: 1}}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch products: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	products := []models.Product{}
+	if err := cursor.All(timeoutCtx, &products); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode products: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Products fetched successfully!", products)
+}
+
+// GetProductByID → GET /products/{id}
+func (pc *ProductController) GetProductByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var product models.Product
+	err = pc.ProductColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&product)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Product not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Product fetched successfully!", product)
+}
+
+// AddProduct → POST /products
+func (pc *ProductController) AddProduct(ctx iris.Context) {
+	var req models.ProductRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	if !normalizeProductRequest(ctx, &req) {
+		return
+	}
+
+	now := time.Now().UTC()
+	product := models.Product{
+		WorkspaceID: workspaceID(ctx),
+		SKU:         req.SKU,
+		Name:        req.Name,
+		Unit:        req.Unit,
+		Category:    req.Category,
+		CreatedBy:   currentUserID(ctx),
+		UpdatedBy:   currentUserID(ctx),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := pc.ProductColl.InsertOne(timeoutCtx, product)
+	if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "SKU already exists", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert product: "+err.Error(), nil)
+		return
+	}
+	product.ID = result.InsertedID.(primitive.ObjectID)
+
+	pc.Audit.Record(ctx, models.AuditCreate, pc.ProductColl.Name(), product.ID, nil, product)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Product created successfully!", product)
+}
+
+// UpdateProductByID → PUT /products/{id}
+// Renaming a SKU doesn't rewrite past logs; their lines keep the product ID.
+func (pc *ProductController) UpdateProductByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	var req models.ProductRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	if !normalizeProductRequest(ctx, &req) {
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var previous models.Product
+	err = pc.ProductColl.FindOneAndUpdate(timeoutCtx, scoped(ctx, bson.M{"_id": objectID}), bson.M{
+		"$set": bson.M{
+			"sku":        req.SKU,
+			"name":       req.Name,
+			"unit":       req.Unit,
+			"category":   req.Category,
+			"updated_by": currentUserID(ctx),
+			"updated_at": time.Now().UTC(),
+		},
+	}).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Product not found", nil)
+		return
+	} else if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "SKU already exists", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to update product: "+err.Error(), nil)
+		return
+	}
+
+	var product models.Product
+	if err := pc.ProductColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&product); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	pc.Audit.Record(ctx, models.AuditUpdate, pc.ProductColl.Name(), objectID, previous, product)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Product updated successfully!", product)
+}
+
+// DeleteProductByID → DELETE /products/{id}
+// Products that appear in any sales log can't be deleted.
+func (pc *ProductController) DeleteProductByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	used, err := pc.SalesColl.CountDocuments(timeoutCtx, scoped(ctx, bson.M{"lines.product_id": objectID}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+	if used > 0 {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "Product is used in sales logs and can't be deleted", nil)
+		return
+	}
+
+	var deleted models.Product
+	err = pc.ProductColl.FindOneAndDelete(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Product not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	pc.Audit.Record(ctx, models.AuditDelete, pc.ProductColl.Name(), objectID, deleted, nil)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Product deleted successfully!", iris.Map{"id": objectID})
+}
+
+// normalizeProductRequest trims and upper-cases the SKU and checks the
+// required fields, writing a 400 when something is missing.
+func normalizeProductRequest(ctx iris.Context, req *models.ProductRequest) bool {
+	req.SKU = strings.ToUpper(strings.TrimSpace(req.SKU))
+	req.Name = strings.TrimSpace(req.Name)
+	req.Unit = strings.TrimSpace(req.Unit)
+	req.Category = strings.TrimSpace(req.Category)
+
+	if req.SKU == "" || req.Name == "" || req.Unit == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "sku, name and unit are required", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ProfileRequest updates the caller's own details. Empty fields are left
+// unchanged; email and role can't be changed here.
+type ProfileRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Phone     string `json:"phone"`
+	DOB       string `json:"dob"`
+	Gender    string `json:"gender"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// GetMe → GET /auth/me
+func (ac *AuthController) GetMe(ctx iris.Context) {
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Profile fetched successfully!", user)
+}
+
+// UpdateMe → PUT /auth/me
+func (ac *AuthController) UpdateMe(ctx iris.Context) {
+	var req ProfileRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	set := bson.M{"updated_by": currentUserID(ctx), "updated_at": time.Now()}
+	if v := strings.TrimSpace(req.FirstName); v != "" {
+		set["first_name"] = v
+	}
+	if v := strings.TrimSpace(req.LastName); v != "" {
+		set["last_name"] = v
+	}
+	if v := strings.TrimSpace(req.Phone); v != "" {
+		set["phone"] = v
+	}
+	if v := strings.TrimSpace(req.Gender); v != "" {
+		set["gender"] = v
+	}
+	if req.DOB != "" {
+		dob, err := time.Parse("2006-01-02", req.DOB)
+		if err != nil {
+			utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "dob must be in YYYY-MM-DD format", nil)
+			return
+		}
+		if dob.After(time.Now()) {
+			utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "dob cannot be in the future", nil)
+			return
+		}
+		set["dob"] = dob
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := ac.UserColl.FindOneAndUpdate(cctx,
+		bson.M{"_id": currentUserID(ctx)},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "User not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Profile updated successfully!", user)
+}
+
+// ChangePassword → POST /auth/change-password
+// Every other session is signed out; the one making the request survives.
+// Wrong current passwords count towards the account's login lockout.
+func (ac *AuthController) ChangePassword(ctx iris.Context) {
+	var req ChangePasswordRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.CurrentPassword == "" || req.NewPassword == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "current_password and new_password required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if !ac.checkPassword(ctx, cctx, user, req.CurrentPassword) {
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to hash password", nil)
+		return
+	}
+
+	_, err = ac.UserColl.UpdateByID(cctx, user.ID, bson.M{
+		"$set": bson.M{"password": string(hashed), "updated_at": time.Now()},
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	if _, err := ac.revokeUserSessions(cctx, user.ID, currentSessionID(ctx)); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Password changed successfully!", nil)
+}
+
+// ChangeEmail → POST /auth/change-email
+// Mails a single-use confirmation link to the new address. The account
+// keeps its current email until that link is opened, and only the newest
+// link works.
+func (ac *AuthController) ChangeEmail(ctx iris.Context) {
+	var req ChangeEmailRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Email == "" || req.Password == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "email and password required", nil)
+		return
+	}
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || addr.Address != strings.TrimSpace(req.Email) {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Invalid email address", nil)
+		return
+	}
+	email := strings.ToLower(addr.Address)
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if !ac.checkPassword(ctx, cctx, user, req.Password) {
+		return
+	}
+	if email == user.Email {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "That is already your email address", nil)
+		return
+	}
+
+	count, err := ac.UserColl.CountDocuments(cctx, bson.M{"email": email})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if count > 0 {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to create token", nil)
+		return
+	}
+	now := time.Now().UTC()
+	if _, err := ac.EmailChangeColl.DeleteMany(cctx, bson.M{"user_id": user.ID}); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	_, err = ac.EmailChangeColl.InsertOne(cctx, models.EmailChange{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ac.VerifyTokenTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	err = ac.Mailer.Send(utils.Mail{
+		To:      email,
+		Subject: "Confirm your new Pai Finance email",
+		Body: "Hi " + user.FirstName + ",\n\n" +
+			"Open the link below to start using this address for your Pai Finance account. It expires in " + ac.VerifyTokenTTL.String() + ".\n\n" +
+			utils.AppURL() + "/confirm-email-change?token=" + token + "\n",
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "Failed to send confirmation email", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Check your new inbox to confirm the change.", nil)
+}
+
+// ConfirmEmailChange → POST /auth/confirm-email-change
+// Consumes the token, so a link can't be replayed.
+func (ac *AuthController) ConfirmEmailChange(ctx iris.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Token == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "token required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	var change models.EmailChange
+	err := ac.EmailChangeColl.FindOneAndDelete(cctx, bson.M{
+		"token_hash": utils.HashToken(req.Token),
+		"expires_at": bson.M{"$gt": time.Now().UTC()},
+	}).Decode(&change)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Confirmation link is invalid or has expired", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	userID, email := change.UserID, change.Email
+
+	// Someone may have registered the address since the link was sent.
+	count, err := ac.UserColl.CountDocuments(cctx, bson.M{"email": email, "_id": bson.M{"$ne": userID}})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if count > 0 {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	}
+
+	var previous models.User
+	err = ac.UserColl.FindOneAndUpdate(cctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"email": email, "email_verified": true, "updated_by": userID, "updated_at": time.Now()}},
+	).Decode(&previous)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Confirmation link is invalid or has expired", nil)
+		return
+	} else if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	if previous.Email != email {
+		go func() {
+			err := ac.Mailer.Send(utils.Mail{
+				To:      previous.Email,
+				Subject: "Your Pai Finance email was changed",
+				Body: "Hi " + previous.FirstName + ",\n\n" +
+					"Your Pai Finance account now uses " + email + ". If you didn't make this change, contact your workspace owner right away.\n",
+			})
+			if err != nil {
+				log.Println("email change notice failed:", err)
+			}
+		}()
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Email changed successfully!", iris.Map{"email": email})
+}
+
+// checkPassword verifies password against user's hash, sharing the login
+// lockout so a stolen session can't be used to guess the password.
+func (ac *AuthController) checkPassword(ctx iris.Context, cctx context.Context, user models.User, password string) bool {
+	accountKey := accountThrottleKey(user.Email)
+
+	wait, err := ac.Throttler.RetryAfter(cctx, accountKey)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return false
+	}
+	if wait > 0 {
+		sendTooManyAttempts(ctx, wait)
+		return false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		if err := ac.Throttler.Fail(cctx, accountKey, ac.Throttler.AccountPolicy); err != nil {
+			log.Println("login throttle update failed:", err)
+		}
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Current password is incorrect", nil)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetSalesLogAnalytics → GET /salesLogs/analytics
+// Aggregates the logs in [from, to] (YYYY-MM-DD) into daily, weekly (ISO),
+// monthly and yearly totals, a moving average of daily sales over the last
+// window days (default 7), day-of-week seasonality and the running
+// difference. to defaults to today and from to 90 days before it. Adds up
+// every store unless narrowed with store_id.
+func (sc *SalesController) GetSalesLogAnalytics(ctx iris.Context) {
+	to := utils.Today()
+	if v := ctx.URLParam("to"); v != "" {
+		t, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -90)
+	if v := ctx.URLParam("from"); v != "" {
+		f, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		from = f
+	}
+	if from.After(to) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must not be after to", nil)
+		return
+	}
+	if to.Sub(from) > maxGapRange*24*time.Hour {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Range is too long; query at most three years at a time", nil)
+		return
+	}
+
+	window := ctx.URLParamIntDefault("window", 7)
+	if window < 2 || window > 90 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "window must be between 2 and 90 days", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match := bson.M{"date": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)}}
+	if v := ctx.URLParam("store_id"); v != "" {
+		store, err := findStore(timeoutCtx, sc.StoreColl, workspaceID(ctx), v)
+		if errors.Is(err, errUnknownStore) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+			return
+		}
+		match["store_id"] = store.ID
+	}
+
+	cursor, err := sc.SalesColl.Aggregate(timeoutCtx, analyticsPipeline(scoped(ctx, match), window))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to aggregate sales-logs: "+err.Error(), nil)
+		return
+	}
+	var results []models.SalesAnalytics
+	if err := cursor.All(timeoutCtx, &results); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode sales-logs: "+err.Error(), nil)
+		return
+	}
+	// $facet always yields one document, with empty arrays when no log matched.
+	analytics := models.SalesAnalytics{
+		Daily:    []models.AnalyticsDay{},
+		Weekly:   []models.AnalyticsPeriod{},
+		Monthly:  []models.AnalyticsPeriod{},
+		Yearly:   []models.AnalyticsPeriod{},
+		Weekdays: []models.AnalyticsWeekday{},
+	}
+	if len(results) > 0 {
+		analytics = results[0]
+	}
+
+	totals := models.AnalyticsPeriod{Period: "total"}
+	if len(analytics.Totals) > 0 {
+		totals = analytics.Totals[0]
+	}
+	for i := range analytics.Weekdays {
+		w := &analytics.Weekdays[i]
+		w.Name = time.Weekday(w.Weekday % 7).String()
+		if totals.AverageDailySales != 0 {
+			w.Index = math.Round(w.AverageSales/totals.AverageDailySales*100) / 100
+		}
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched sales analytics successfully!", iris.Map{
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"window":   window,
+		"totals":   totals,
+		"daily":    analytics.Daily,
+		"weekly":   analytics.Weekly,
+		"monthly":  analytics.Monthly,
+		"yearly":   analytics.Yearly,
+		"weekdays": analytics.Weekdays,
+	})
+}
+
+// analyticsPipeline adds the matched logs up per day, then fans the days out
+// into each view. Days without a log are left out rather than counted as
+// zero, so averages are per trading day.
+func analyticsPipeline(match bson.M, window int) mongo.Pipeline {
+	figures := func() bson.M {
+		return bson.M{
+			"sales":      bson.M{"$sum": "$sales"},
+			"inward":     bson.M{"$sum": "$inward"},
+			"outward":    bson.M{"$sum": "$outward"},
+			"difference": bson.M{"$sum": "$difference"},
+			"adjustment": bson.M{"$sum": "$adjustment"},
+		}
+	}
+	period := func(id interface{}) bson.A {
+		group := figures()
+		group["_id"] = id
+		group["start"] = bson.M{"$min": "$_id"}
+		group["end"] = bson.M{"$max": "$_id"}
+		group["days"] = bson.M{"$sum": 1}
+		group["average_daily_sales"] = bson.M{"$avg": "$sales"}
+		return bson.A{
+			bson.M{"$group": group},
+			bson.M{"$sort": bson.M{"start": 1}},
+		}
+	}
+	byFormat := func(format string) bson.A {
+		return period(bson.M{"$dateToString": bson.M{"format": format, "date": "$_id"}})
+	}
+
+	daily := figures()
+	daily["_id"] = "$date"
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: daily}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		{{Key: "$facet", Value: bson.M{
+			"daily": bson.A{
+				bson.M{"$setWindowFields": bson.M{
+					"sortBy": bson.M{"_id": 1},
+					"output": bson.M{
+						"moving_average": bson.M{
+							"$avg":   "$sales",
+							"window": bson.M{"range": bson.A{-(window - 1), 0}, "unit": "day"},
+						},
+						"cumulative_difference": bson.M{
+							"$sum":   "$difference",
+							"window": bson.M{"documents": bson.A{"unbounded", "current"}},
+						},
+					},
+				}},
+			},
+			"weekly":  byFormat("%G-W%V"),
+			"monthly": byFormat("%Y-%m"),
+			"yearly":  byFormat("%Y"),
+			"totals":  period("total"),
+			"weekdays": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":           bson.M{"$isoDayOfWeek": "$_id"},
+					"days":          bson.M{"$sum": 1},
+					"sales":         bson.M{"$sum": "$sales"},
+					"average_sales": bson.M{"$avg": "$sales"},
+				}},
+				bson.M{"$sort": bson.M{"_id": 1}},
+			},
+		}}},
+	}
+}
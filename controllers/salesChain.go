@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errDuplicateDay      = errors.New("a sales log already exists for this day")
+	errLogHasTransfers   = errors.New("sales log has stock transfers")
+	errLogHasAdjustments = errors.New("sales log has approved stock adjustments")
+)
+
+// chainUpdate is a log whose stock figures were rewritten by recomputeChain.
+type chainUpdate struct {
+	Before models.SalesLog
+	After  models.SalesLog
+}
+
+// inTransaction runs fn in a Mongo transaction so a change and the chain
+// recomputation it triggers are committed together or not at all.
+// Transactions need a replica set; Atlas clusters always are one.
+func (sc *SalesController) inTransaction(cctx context.Context, fn func(sctx mongo.SessionContext) error) error {
+	session, err := sc.SalesColl.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(cctx)
+
+	_, err = session.WithTransaction(cctx, func(sctx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sctx)
+	})
+	return err
+}
+
+// recomputeChain walks every log of a store dated on or after from in date
+// order, carrying Opening forward from the Physical of the most recent
+// earlier log, then re-deriving Physical and Difference. Days without a log
+// in between don't break the chain. The first log of a store has no earlier
+// log and keeps its stored Opening, which is how the chain is seeded.
+// Transfers to and from other stores count like inward and outward, and
+// approved stock adjustments are added as they are.
+//
+// Logs with product lines chain per product instead, and their shop-level
+// figures are the rollup of the lines.
+func (sc *SalesController) recomputeChain(sctx mongo.SessionContext, workspace, store primitive.ObjectID, from time.Time) ([]chainUpdate, error) {
+	var prev models.SalesLog
+	hasPrev := true
+	err := sc.SalesColl.FindOne(sctx,
+		bson.M{"workspace_id": workspace, "store_id": store, "date": bson.M{"$lt": from}},
+		options.FindOne().SetSort(bson.D{{Key: "date", Value: -1}}),
+	).Decode(&prev)
+	if err == mongo.ErrNoDocuments {
+		hasPrev = false
+	} else if err != nil {
+		return nil, err
+	}
+
+	balances, err := sc.lineBalances(sctx, workspace, store, from)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := sc.SalesColl.Find(sctx,
+		bson.M{"workspace_id": workspace, "store_id": store, "date": bson.M{"$gte": from}},
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var logs []models.SalesLog
+	if err := cursor.All(sctx, &logs); err != nil {
+		return nil, err
+	}
+
+	var updates []chainUpdate
+	now := time.Now()
+	for _, log := range logs {
+		next := log
+		if len(log.Lines) > 0 {
+			next.Lines = append([]models.SalesLogLine(nil), log.Lines...)
+			for i := range next.Lines {
+				line := &next.Lines[i]
+				if balance, ok := balances[line.ProductID]; ok {
+					line.Opening = balance
+				}
+				line.Physical = line.Opening + line.Inward + line.TransferIn - line.Outward - line.TransferOut - line.Sales + line.Adjustment
+				line.Difference = line.System - line.Opening
+				balances[line.ProductID] = line.Physical
+			}
+			rollUp(&next)
+		} else {
+			if hasPrev {
+				next.Opening = prev.Physical
+			}
+			next.Physical = next.Opening + next.Inward + next.TransferIn - next.Outward - next.TransferOut - next.Sales + next.Adjustment
+			next.Difference = next.System - next.Opening
+		}
+
+		if stockChanged(log, next) {
+			next.UpdatedAt = now
+			set := bson.M{
+				"opening":      next.Opening,
+				"inward":       next.Inward,
+				"sales":        next.Sales,
+				"outward":      next.Outward,
+				"transfer_in":  next.TransferIn,
+				"transfer_out": next.TransferOut,
+				"adjustment":   next.Adjustment,
+				"physical":     next.Physical,
+				"system":       next.System,
+				"difference":   next.Difference,
+				"updated_at":   next.UpdatedAt,
+			}
+			if len(next.Lines) > 0 {
+				set["lines"] = next.Lines
+			}
+			if _, err := sc.SalesColl.UpdateByID(sctx, log.ID, bson.M{"$set": set}); err != nil {
+				return nil, err
+			}
+			updates = append(updates, chainUpdate{Before: log, After: next})
+		}
+
+		prev, hasPrev = next, true
+	}
+	return updates, nil
+}
+
+// stockChanged reports whether recomputation changed any figure of a log.
+func stockChanged(before, after models.SalesLog) bool {
+	if before.Opening != after.Opening || before.Inward != after.Inward ||
+		before.Sales != after.Sales || before.Outward != after.Outward ||
+		before.TransferIn != after.TransferIn || before.TransferOut != after.TransferOut ||
+		before.Adjustment != after.Adjustment ||
+		before.Physical != after.Physical || before.System != after.System ||
+		before.Difference != after.Difference || len(before.Lines) != len(after.Lines) {
+		return true
+	}
+	for i := range before.Lines {
+		if before.Lines[i] != after.Lines[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// missingDays lists the days in [from, to] that have no log and aren't
+// holidays.
+func missingDays(from, to time.Time, logged map[string]bool, cal holidayCalendar) []time.Time {
+	var missing []time.Time
+	for day := utils.CalendarDay(from); !day.After(utils.CalendarDay(to)); day = day.AddDate(0, 0, 1) {
+		if !logged[utils.DayKey(day)] && !cal.closed(day) {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
+
+// affectedDays summarises recomputed logs for API responses.
+func affectedDays(updates []chainUpdate) []models.AffectedDay {
+	days := make([]models.AffectedDay, 0, len(updates))
+	for _, u := range updates {
+		days = append(days, models.AffectedDay{
+			ID:         u.After.ID,
+			StoreID:    u.After.StoreID,
+			Date:       u.After.Date,
+			Opening:    u.After.Opening,
+			Physical:   u.After.Physical,
+			Difference: u.After.Difference,
+		})
+	}
+	return days
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetConsolidatedSalesLogs → GET /salesLogs/consolidated
+// Adds up every store's logs per day in [from, to] (YYYY-MM-DD). to defaults
+// to today and from to 30 days before it. Each day lists the stores that
+// logged it, so days some stores missed are easy to spot.
+func (sc *SalesController) GetConsolidatedSalesLogs(ctx iris.Context) {
+	to := utils.Today()
+	if v := ctx.URLParam("to"); v != "" {
+		t, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if v := ctx.URLParam("from"); v != "" {
+		f, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		from = f
+	}
+	if from.After(to) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must not be after to", nil)
+		return
+	}
+	if to.Sub(from) > maxGapRange*24*time.Hour {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Range is too long; query at most three years at a time", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := sc.SalesColl.Aggregate(timeoutCtx, mongo.Pipeline{
+		{{Key: "$match", Value: scoped(ctx, bson.M{"date": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$date",
+			"opening":     bson.M{"$sum": "$opening"},
+			"inward":      bson.M{"$sum": "$inward"},
+			"sales":       bson.M{"$sum": "$sales"},
+			"outward":     bson.M{"$sum": "$outward"},
+			"transferred": bson.M{"$sum": "$transfer_in"},
+			"adjustment":  bson.M{"$sum": "$adjustment"},
+			"physical":    bson.M{"$sum": "$physical"},
+			"system":      bson.M{"$sum": "$system"},
+			"difference":  bson.M{"$sum": "$difference"},
+			"stores": bson.M{"$push": bson.M{
+				"store_id":     "$store_id",
+				"log_id":       "$_id",
+				"opening":      "$opening",
+				"inward":       "$inward",
+				"sales":        "$sales",
+				"outward":      "$outward",
+				"transfer_in":  "$transfer_in",
+				"transfer_out": "$transfer_out",
+				"adjustment":   "$adjustment",
+				"physical":     "$physical",
+				"system":       "$system",
+				"difference":   "$difference",
+			}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to aggregate sales-logs: "+err.Error(), nil)
+		return
+	}
+	days := []models.ConsolidatedDay{}
+	if err := cursor.All(timeoutCtx, &days); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode sales-logs: "+err.Error(), nil)
+		return
+	}
+
+	names, err := storeNames(timeoutCtx, sc.StoreColl, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch stores: "+err.Error(), nil)
+		return
+	}
+	for i := range days {
+		for j := range days[i].Stores {
+			days[i].Stores[j].StoreName = names[days[i].Stores[j].StoreID]
+		}
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched consolidated sales-logs successfully!", iris.Map{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"stores": len(names),
+		"days":   days,
+	})
+}
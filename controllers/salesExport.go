@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var salesExportColumns = []string{"Date", "Store", "Opening", "Inward", "Transfer in", "Sales", "Outward", "Transfer out", "Adjustment", "Physical", "System", "Difference"}
+
+// salesTotals sums the movement columns of exported logs.
+type salesTotals struct {
+	inward, transferIn, sales, outward, transferOut, adjustment int
+}
+
+func (t *salesTotals) add(l models.SalesLog) {
+	t.inward += l.Inward
+	t.transferIn += l.TransferIn
+	t.sales += l.Sales
+	t.outward += l.Outward
+	t.transferOut += l.TransferOut
+	t.adjustment += l.Adjustment
+}
+
+// ExportSalesLogs → GET /salesLogs/export
+// Takes GetSalesLogs' store_id plus from/to (YYYY-MM-DD) and format (csv,
+// xlsx or pdf).
+// Rows are streamed from the cursor in date order. XLSX adds a sheet of
+// per-product lines.
+func (sc *SalesController) ExportSalesLogs(ctx iris.Context) {
+	filter, ok := salesLogExportFilter(ctx)
+	if !ok {
+		return
+	}
+
+	cctx, cancel := exportContext(ctx)
+	defer cancel()
+
+	names, err := storeNames(cctx, sc.StoreColl, workspaceID(ctx))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch stores: "+err.Error(), nil)
+		return
+	}
+
+	cursor, err := sc.SalesColl.Find(cctx, filter,
+		options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "store_id", Value: 1}}).SetBatchSize(500),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch sales-logs: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(cctx)
+
+	format, ok := startExport(ctx, "sales-logs")
+	if !ok {
+		return
+	}
+
+	// Headers are already sent, so failures past here can only be logged.
+	switch format {
+	case "csv":
+		err = exportSalesCSV(ctx, cctx, cursor, names)
+	case "xlsx":
+		err = exportSalesXLSX(ctx, cctx, cursor, names)
+	case "pdf":
+		err = exportSalesPDF(ctx, cctx, cursor, names)
+	}
+	if err != nil {
+		log.Println("sales-log export failed:", err)
+	}
+}
+
+func salesRecord(l models.SalesLog, names map[primitive.ObjectID]string) []string {
+	return []string{
+		utils.DayKey(l.Date), names[l.StoreID],
+		strconv.Itoa(l.Opening), strconv.Itoa(l.Inward), strconv.Itoa(l.TransferIn),
+		strconv.Itoa(l.Sales), strconv.Itoa(l.Outward), strconv.Itoa(l.TransferOut), strconv.Itoa(l.Adjustment),
+		strconv.Itoa(l.Physical), strconv.Itoa(l.System), strconv.Itoa(l.Difference),
+	}
+}
+
+func exportSalesCSV(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor, names map[primitive.ObjectID]string) error {
+	w := csv.NewWriter(ctx.ResponseWriter())
+	if err := w.Write(salesExportColumns); err != nil {
+		return err
+	}
+
+	for n := 1; cursor.Next(cctx); n++ {
+		var l models.SalesLog
+		if err := cursor.Decode(&l); err != nil {
+			return err
+		}
+		if err := w.Write(salesRecord(l, names)); err != nil {
+			return err
+		}
+		if n%exportFlushEvery == 0 {
+			w.Flush()
+			ctx.ResponseWriter().Flush()
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return cursor.Err()
+}
+
+func exportSalesXLSX(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor, names map[primitive.ObjectID]string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+	if err := f.SetSheetName("Sheet1", "Sales logs"); err != nil {
+		return err
+	}
+	if _, err := f.NewSheet("Product lines"); err != nil {
+		return err
+	}
+
+	// Stream writers spill rows to a temp file once a sheet grows large.
+	logsSheet, err := f.NewStreamWriter("Sales logs")
+	if err != nil {
+		return err
+	}
+	linesSheet, err := f.NewStreamWriter("Product lines")
+	if err != nil {
+		return err
+	}
+	if err := logsSheet.SetRow("A1", headerRow(styles.header, salesExportColumns...)); err != nil {
+		return err
+	}
+	if err := linesSheet.SetRow("A1", headerRow(styles.header, "Date", "Store", "SKU", "Opening", "Inward", "Transfer in", "Sales", "Outward", "Transfer out", "Adjustment", "Physical", "System", "Difference")); err != nil {
+		return err
+	}
+
+	num := func(n int) excelize.Cell { return excelize.Cell{StyleID: styles.integer, Value: n} }
+	var totals salesTotals
+	row, lineRow := 2, 2
+	for cursor.Next(cctx) {
+		var l models.SalesLog
+		if err := cursor.Decode(&l); err != nil {
+			return err
+		}
+		totals.add(l)
+
+		date := excelize.Cell{StyleID: styles.date, Value: l.Date}
+		cell, _ := excelize.CoordinatesToCellName(1, row)
+		if err := logsSheet.SetRow(cell, []interface{}{
+			date, names[l.StoreID],
+			num(l.Opening), num(l.Inward), num(l.TransferIn), num(l.Sales), num(l.Outward), num(l.TransferOut), num(l.Adjustment),
+			num(l.Physical), num(l.System), num(l.Difference),
+		}); err != nil {
+			return err
+		}
+		row++
+
+		for _, line := range l.Lines {
+			cell, _ := excelize.CoordinatesToCellName(1, lineRow)
+			if err := linesSheet.SetRow(cell, []interface{}{
+				date, names[l.StoreID], line.SKU,
+				num(line.Opening), num(line.Inward), num(line.TransferIn), num(line.Sales), num(line.Outward), num(line.TransferOut), num(line.Adjustment),
+				num(line.Physical), num(line.System), num(line.Difference),
+			}); err != nil {
+				return err
+			}
+			lineRow++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	cell, _ := excelize.CoordinatesToCellName(1, row+1)
+	if err := logsSheet.SetRow(cell, []interface{}{
+		excelize.Cell{StyleID: styles.header, Value: "Total"}, "", "",
+		num(totals.inward), num(totals.transferIn), num(totals.sales), num(totals.outward), num(totals.transferOut), num(totals.adjustment),
+	}); err != nil {
+		return err
+	}
+
+	if err := logsSheet.Flush(); err != nil {
+		return err
+	}
+	if err := linesSheet.Flush(); err != nil {
+		return err
+	}
+	_, err = f.WriteTo(ctx.ResponseWriter())
+	return err
+}
+
+func exportSalesPDF(ctx iris.Context, cctx context.Context, cursor *mongo.Cursor, names map[primitive.ObjectID]string) error {
+	widths := []float64{24, 36, 21, 21, 21, 21, 21, 21, 21, 21, 21, 21}
+	columns := make([]utils.PDFColumn, len(salesExportColumns))
+	for i, title := range salesExportColumns {
+		columns[i] = utils.PDFColumn{Title: title, Width: widths[i], Align: "R"}
+	}
+	columns[0].Align, columns[1].Align = "L", "L"
+
+	report := utils.NewPDFReport("Sales logs", exportPeriod(ctx), columns)
+	qty := func(n int) string { return utils.FormatIndian(float64(n), 0) }
+
+	var totals salesTotals
+	for cursor.Next(cctx) {
+		var l models.SalesLog
+		if err := cursor.Decode(&l); err != nil {
+			return err
+		}
+		totals.add(l)
+		report.Row(
+			l.Date.Format("02 Jan 2006"), names[l.StoreID],
+			qty(l.Opening), qty(l.Inward), qty(l.TransferIn), qty(l.Sales), qty(l.Outward), qty(l.TransferOut), qty(l.Adjustment),
+			qty(l.Physical), qty(l.System), qty(l.Difference),
+		)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	report.Totals("Total", "", "",
+		qty(totals.inward), qty(totals.transferIn), qty(totals.sales), qty(totals.outward), qty(totals.transferOut), qty(totals.adjustment))
+
+	return report.Write(ctx.ResponseWriter())
+}
+
+// salesLogExportFilter narrows by store_id the way GetSalesLogs does, and by
+// from/to (YYYY-MM-DD) so a report can cover one period. It writes a 400 and
+// returns false on bad input.
+func salesLogExportFilter(ctx iris.Context) (bson.M, bool) {
+	filter := scoped(ctx, bson.M{})
+	if v := ctx.URLParam("store_id"); v != "" {
+		storeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid store_id", nil)
+			return nil, false
+		}
+		filter["store_id"] = storeID
+	}
+
+	dates := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		dates["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return nil, false
+		}
+		dates["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(dates) > 0 {
+		filter["date"] = dates
+	}
+	return filter, true
+}
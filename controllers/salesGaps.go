@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxGapRange bounds how many days a single gaps query walks.
+const maxGapRange = 3 * 366
+
+// GetSalesLogGaps → GET /salesLogs/gaps
+// Lists business days in [from, to] (YYYY-MM-DD) that have no log. from
+// defaults to the first log and to to today. Holidays are skipped unless holidays=false.
+// Checks the default store unless store_id is given.
+func (sc *SalesController) GetSalesLogGaps(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store, err := findStore(timeoutCtx, sc.StoreColl, workspaceID(ctx), ctx.URLParam("store_id"))
+	if errors.Is(err, errUnknownStore) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+		return
+	}
+
+	to := utils.Today()
+	if v := ctx.URLParam("to"); v != "" {
+		t, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		to = t
+	}
+
+	var from time.Time
+	if v := ctx.URLParam("from"); v != "" {
+		f, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		from = f
+	} else {
+		var first models.SalesLog
+		err := sc.SalesColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"store_id": store.ID}),
+			options.FindOne().SetSort(bson.D{{Key: "date", Value: 1}}),
+		).Decode(&first)
+		if err == mongo.ErrNoDocuments {
+			utils.SendResponse(ctx, http.StatusOK, "success", "No sales logs yet", iris.Map{"missing": []time.Time{}, "count": 0})
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+			return
+		}
+		from = utils.CalendarDay(first.Date)
+	}
+
+	if from.After(to) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must not be after to", nil)
+		return
+	}
+	if to.Sub(from) > maxGapRange*24*time.Hour {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Range is too long; query at most three years at a time", nil)
+		return
+	}
+
+	cursor, err := sc.SalesColl.Find(timeoutCtx,
+		scoped(ctx, bson.M{"store_id": store.ID, "date": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)}}),
+		options.Find().SetProjection(bson.M{"date": 1}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch sales-logs: "+err.Error(), nil)
+		return
+	}
+	var logs []models.SalesLog
+	if err := cursor.All(timeoutCtx, &logs); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode sales-logs: "+err.Error(), nil)
+		return
+	}
+	logged := map[string]bool{}
+	for _, l := range logs {
+		logged[utils.DayKey(l.Date)] = true
+	}
+
+	useHolidays := ctx.URLParamBoolDefault("holidays", true)
+	cal := holidayCalendar{}
+	if useHolidays {
+		cal, err = loadHolidayCalendar(timeoutCtx, sc.HolidayColl, workspaceID(ctx))
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch holidays: "+err.Error(), nil)
+			return
+		}
+	}
+
+	missing := missingDays(from, to, logged, cal)
+	if missing == nil {
+		missing = []time.Time{}
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched sales-log gaps successfully!", iris.Map{
+		"store_id": store.ID,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"holidays": useHolidays,
+		"missing":  missing,
+		"count":    len(missing),
+	})
+}
@@ -0,0 +1,461 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxImportSize bounds uploaded spreadsheets.
+const maxImportSize = 10 << 20
+
+// importFields are the columns an import can map. Only date is required.
+var importFields = []string{"date", "sku", "opening", "inward", "sales", "outward", "system"}
+
+// importDateFormats are the date_format values an import accepts. Without
+// one, only the formats that can't be misread are tried; numeric day/month
+// dates such as 03/04/2025 need date_format so one file can't mix orders.
+var importDateFormats = []struct {
+	name, layout string
+	ambiguous    bool
+}{
+	{"YYYY-MM-DD", "2006-01-02", false},
+	{"DD/MM/YYYY", "2/1/2006", true},
+	{"DD-MM-YYYY", "2-1-2006", true},
+	{"DD-MMM-YYYY", "2-Jan-2006", false},
+	{"MM/DD/YYYY", "1/2/2006", true},
+}
+
+// earliestImportDay is the first day an import may hold; anything earlier is
+// taken for a typo rather than history.
+var earliestImportDay = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// numericDayMonth matches dates whose day and month order is ambiguous.
+var numericDayMonth = regexp.MustCompile(`^\d{1,2}[/-]\d{1,2}[/-]\d{4}$`)
+
+// errDryRun rolls back an import once its preview has been computed.
+var errDryRun = errors.New("dry run")
+
+// ImportSalesLogs → POST /salesLogs/import
+// Takes a multipart upload: file (.csv or .xlsx), and optionally store_id,
+// sheet, date_format (required for numeric dates like 03/04/2025), mapping
+// (a JSON object of field → column header, by default each field's own
+// name) and commit. Every row is validated; rows that share a date become
+// one log, with per-product lines when sku is mapped. opening only seeds a
+// store's first log, as with AddSalesLog. Dates must fall between 2000 and
+// tomorrow, and only XLSX files may hold them as Excel serial numbers.
+//
+// By default nothing is saved: the import runs in a transaction that is
+// rolled back, and the response previews the logs it would create with
+// their recomputed figures. commit=true saves them, all or nothing.
+func (sc *SalesController) ImportSalesLogs(ctx iris.Context) {
+	file, header, err := ctx.FormFile("file")
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "file is required", nil)
+		return
+	}
+	defer file.Close()
+	if header.Size > maxImportSize {
+		utils.SendResponse(ctx, http.StatusRequestEntityTooLarge, "error", "File is too large; split it into files under 10 MB", nil)
+		return
+	}
+
+	mapping := map[string]string{}
+	if v := ctx.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &mapping); err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "mapping must be a JSON object of field to column header", nil)
+			return
+		}
+	}
+	for field := range mapping {
+		if !containsString(importFields, field) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown mapping field "+field+"; use one of "+strings.Join(importFields, ", "), nil)
+			return
+		}
+	}
+
+	layouts, ok := importLayouts(ctx.FormValue("date_format"))
+	if !ok {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown date_format", nil)
+		return
+	}
+
+	rows, err := utils.ReadSpreadsheet(file, header.Filename, ctx.FormValue("sheet"))
+	if errors.Is(err, utils.ErrUnsupportedSpreadsheet) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", err.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Couldn't read the file: "+err.Error(), nil)
+		return
+	}
+	if len(rows) < 2 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "The file has no rows below the header", nil)
+		return
+	}
+
+	columns, err := importColumns(rows[0], mapping)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", err.Error(), nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	store, err := findStore(timeoutCtx, sc.StoreColl, workspaceID(ctx), ctx.FormValue("store_id"))
+	if errors.Is(err, errUnknownStore) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+		return
+	}
+
+	serials := strings.EqualFold(filepath.Ext(header.Filename), ".xlsx")
+	logs, rowErrors, err := sc.buildImport(ctx, timeoutCtx, store, rows, columns, layouts, serials)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to validate import: "+err.Error(), nil)
+		return
+	}
+	commit := ctx.FormValue("commit") == "true"
+	if len(rowErrors) > 0 {
+		data := iris.Map{"rows": len(rows) - 1, "errors": rowErrors, "committed": false}
+		if commit {
+			utils.SendResponse(ctx, http.StatusUnprocessableEntity, "error", fmt.Sprintf("%d row(s) have errors; nothing was imported", len(rowErrors)), data)
+		} else {
+			utils.SendResponse(ctx, http.StatusOK, "success", fmt.Sprintf("Dry run: %d row(s) have errors; fix them before committing", len(rowErrors)), data)
+		}
+		return
+	}
+
+	var updates []chainUpdate
+	imported := make(map[primitive.ObjectID]bool, len(logs))
+	docs := make([]interface{}, len(logs))
+	for i, l := range logs {
+		imported[l.ID] = true
+		docs[i] = l
+	}
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		if _, err := sc.SalesColl.InsertMany(sctx, docs); mongo.IsDuplicateKeyError(err) {
+			return errDuplicateDay
+		} else if err != nil {
+			return err
+		}
+		var err error
+		updates, err = sc.recomputeChain(sctx, store.WorkspaceID, store.ID, logs[0].Date)
+		if err != nil {
+			return err
+		}
+		if !commit {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDuplicateDay) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "A sales log was added for one of these days while importing; validate the file again", nil)
+		return
+	} else if err != nil && !errors.Is(err, errDryRun) {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to import sales-logs: "+err.Error(), nil)
+		return
+	}
+
+	// The chain fills in Physical and Difference, and Opening after the first.
+	var affected []chainUpdate
+	byID := make(map[primitive.ObjectID]int, len(logs))
+	for i, l := range logs {
+		byID[l.ID] = i
+	}
+	for _, u := range updates {
+		if imported[u.After.ID] {
+			logs[byID[u.After.ID]] = u.After
+		} else {
+			affected = append(affected, u)
+		}
+	}
+
+	if commit {
+		for _, l := range logs {
+			sc.Audit.Record(ctx, models.AuditCreate, sc.SalesColl.Name(), l.ID, nil, l)
+		}
+		for _, u := range affected {
+			sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+		}
+	}
+
+	message := fmt.Sprintf("Dry run: %d sales log(s) would be imported. Send commit=true to save them.", len(logs))
+	if commit {
+		message = fmt.Sprintf("Imported %d sales log(s) successfully!", len(logs))
+	}
+	utils.SendResponse(ctx, http.StatusOK, "success", message, iris.Map{
+		"rows":      len(rows) - 1,
+		"store_id":  store.ID,
+		"logs":      logs,
+		"affected":  affectedDays(affected),
+		"errors":    []models.ImportRowError{},
+		"committed": commit,
+	})
+}
+
+// importColumns maps each import field to its column index in header.
+// Unmapped fields are matched to a header of the same name.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	columns := map[string]int{}
+	for _, field := range importFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q mapped to %s isn't in the header row", name, field)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("no date column; map one with mapping")
+	}
+	return columns, nil
+}
+
+// buildImport validates every row and groups them into logs for store, one
+// per date, sorted by date. Days the store already has a log for are errors.
+// serials accepts Excel date serial numbers, which only XLSX files hold.
+func (sc *SalesController) buildImport(ctx iris.Context, cctx context.Context, store models.Store, rows [][]string, columns map[string]int, layouts []string, serials bool) ([]models.SalesLog, []models.ImportRowError, error) {
+	rowErrors := []models.ImportRowError{}
+	fail := func(row int, column, format string, args ...interface{}) {
+		rowErrors = append(rowErrors, models.ImportRowError{Row: row, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+	_, byProduct := columns["sku"]
+
+	type dayRows struct {
+		firstRow int
+		log      models.SalesLog
+		lines    []models.SalesLogLineRequest
+		lineRows []int
+	}
+	days := map[string]*dayRows{}
+	now := time.Now()
+	// A day ahead allows for a file from a timezone already past midnight.
+	latest := utils.Today().AddDate(0, 0, 1)
+
+	for i, row := range rows[1:] {
+		rowNum := i + 2
+		cell := func(field string) string {
+			c, ok := columns[field]
+			if !ok || c >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[c])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		day, err := parseImportDay(cell("date"), layouts, serials)
+		if err != nil && ctx.FormValue("date_format") == "" && numericDayMonth.MatchString(cell("date")) {
+			fail(rowNum, "date", "%q could be day/month or month/day; set date_format", cell("date"))
+			continue
+		} else if err != nil {
+			fail(rowNum, "date", "%q isn't a date", cell("date"))
+			continue
+		} else if day.Before(earliestImportDay) || day.After(latest) {
+			fail(rowNum, "date", "%s is outside %s to %s", utils.DayKey(day), utils.DayKey(earliestImportDay), utils.DayKey(latest))
+			continue
+		}
+
+		var figures [5]int
+		bad := false
+		for j, field := range []string{"opening", "inward", "sales", "outward", "system"} {
+			n, err := parseImportInt(cell(field))
+			if err != nil {
+				fail(rowNum, field, "%q isn't a whole number", cell(field))
+				bad = true
+			} else if n < 0 {
+				fail(rowNum, field, "can't be negative")
+				bad = true
+			}
+			figures[j] = n
+		}
+		if bad {
+			continue
+		}
+
+		key := utils.DayKey(day)
+		d, seen := days[key]
+		if seen && !byProduct {
+			fail(rowNum, "date", "%s already appears in row %d", key, d.firstRow)
+			continue
+		}
+		if !seen {
+			d = &dayRows{firstRow: rowNum, log: models.SalesLog{
+				ID:          primitive.NewObjectID(),
+				WorkspaceID: store.WorkspaceID,
+				StoreID:     store.ID,
+				Date:        day,
+				CreatedBy:   currentUserID(ctx),
+				UpdatedBy:   currentUserID(ctx),
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}}
+			days[key] = d
+		}
+
+		if byProduct {
+			if cell("sku") == "" {
+				fail(rowNum, "sku", "is required when sku is mapped")
+				continue
+			}
+			d.lines = append(d.lines, models.SalesLogLineRequest{
+				SKU:     cell("sku"),
+				Opening: figures[0],
+				Inward:  figures[1],
+				Sales:   figures[2],
+				Outward: figures[3],
+				System:  figures[4],
+			})
+			d.lineRows = append(d.lineRows, rowNum)
+			continue
+		}
+		d.log.Opening, d.log.Inward, d.log.Sales, d.log.Outward, d.log.System = figures[0], figures[1], figures[2], figures[3], figures[4]
+	}
+
+	if len(days) == 0 {
+		if len(rowErrors) == 0 {
+			fail(2, "", "no rows to import")
+		}
+		return nil, rowErrors, nil
+	}
+
+	logs := make([]models.SalesLog, 0, len(days))
+	firstRows := map[string]int{}
+	for key, d := range days {
+		if byProduct && len(d.lines) > 0 {
+			lines, err := sc.resolveLines(cctx, store.WorkspaceID, d.lines, nil)
+			var lineErr *lineError
+			if errors.As(err, &lineErr) {
+				fail(d.firstRow, "sku", "%s: %s (rows %s)", key, lineErr.Error(), joinInts(d.lineRows))
+				continue
+			} else if err != nil {
+				return nil, nil, err
+			}
+			d.log.Lines = lines
+			rollUp(&d.log)
+		} else if byProduct {
+			continue
+		}
+		logs = append(logs, d.log)
+		firstRows[key] = d.firstRow
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Date.Before(logs[j].Date) })
+	if len(logs) == 0 {
+		return nil, rowErrors, nil
+	}
+
+	cursor, err := sc.SalesColl.Find(cctx, bson.M{
+		"workspace_id": store.WorkspaceID,
+		"store_id":     store.ID,
+		"date":         bson.M{"$gte": logs[0].Date, "$lt": logs[len(logs)-1].Date.AddDate(0, 0, 1)},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var existing []models.SalesLog
+	if err := cursor.All(cctx, &existing); err != nil {
+		return nil, nil, err
+	}
+	for _, e := range existing {
+		if row, ok := firstRows[utils.DayKey(e.Date)]; ok {
+			fail(row, "date", "%s already has a sales log in %s", utils.DayKey(e.Date), store.Name)
+		}
+	}
+
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+	return logs, rowErrors, nil
+}
+
+// importLayouts returns the time layouts for a date_format, or the
+// unambiguous ones when it is empty.
+func importLayouts(format string) ([]string, bool) {
+	var layouts []string
+	for _, f := range importDateFormats {
+		if (format == "" && !f.ambiguous) || strings.EqualFold(format, f.name) {
+			layouts = append(layouts, f.layout)
+		}
+	}
+	return layouts, len(layouts) > 0
+}
+
+// parseImportDay reads a calendar date in one of layouts or, when serials
+// is set for XLSX files, an Excel date serial number as their cells hold them.
+func parseImportDay(v string, layouts []string, serials bool) (time.Time, error) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return utils.CalendarDay(t), nil
+		}
+	}
+	if serials {
+		if serial, err := strconv.ParseFloat(v, 64); err == nil {
+			return utils.ExcelSerialDay(serial)
+		}
+	}
+	return time.Time{}, errors.New("not a date")
+}
+
+// parseImportInt reads a whole number, allowing thousands separators and
+// treating a blank cell as zero.
+func parseImportInt(v string) (int, error) {
+	v = strings.ReplaceAll(v, ",", "")
+	if v == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(v); err == nil {
+		return n, nil
+	}
+	// XLSX stores every number as a float.
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, errors.New("not a whole number")
+	}
+	return int(f), nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
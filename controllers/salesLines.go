@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// lineError is a problem with posted per-product lines, reported as a 400.
+type lineError struct {
+	msg string
+}
+
+func (e *lineError) Error() string { return e.msg }
+
+// resolveLines matches posted lines to catalogue products by SKU. existing
+// holds the lines already stored per product; their Opening wins over the
+// posted one so editing a log can't reseed a product's chain by accident,
+// and their transfers and adjustments are kept since they are posted
+// separately.
+func (sc *SalesController) resolveLines(cctx context.Context, workspace primitive.ObjectID, reqs []models.SalesLogLineRequest, existing map[primitive.ObjectID]models.SalesLogLine) ([]models.SalesLogLine, error) {
+	skus := make([]string, 0, len(reqs))
+	seen := map[string]bool{}
+	for i := range reqs {
+		reqs[i].SKU = strings.ToUpper(strings.TrimSpace(reqs[i].SKU))
+		if reqs[i].SKU == "" {
+			return nil, &lineError{"every line needs a sku"}
+		}
+		if seen[reqs[i].SKU] {
+			return nil, &lineError{"sku " + reqs[i].SKU + " appears more than once"}
+		}
+		seen[reqs[i].SKU] = true
+		skus = append(skus, reqs[i].SKU)
+	}
+
+	cursor, err := sc.ProductColl.Find(cctx, bson.M{"workspace_id": workspace, "sku": bson.M{"$in": skus}})
+	if err != nil {
+		return nil, err
+	}
+	var products []models.Product
+	if err := cursor.All(cctx, &products); err != nil {
+		return nil, err
+	}
+	bySKU := map[string]models.Product{}
+	for _, p := range products {
+		bySKU[p.SKU] = p
+	}
+
+	lines := make([]models.SalesLogLine, 0, len(reqs))
+	for _, r := range reqs {
+		product, ok := bySKU[r.SKU]
+		if !ok {
+			return nil, &lineError{fmt.Sprintf("unknown sku %s; add it to the product catalogue first", r.SKU)}
+		}
+		line := models.SalesLogLine{
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Opening:   r.Opening,
+			Inward:    r.Inward,
+			Sales:     r.Sales,
+			Outward:   r.Outward,
+			System:    r.System,
+		}
+		if prev, ok := existing[product.ID]; ok {
+			line.Opening = prev.Opening
+			line.TransferIn = prev.TransferIn
+			line.TransferOut = prev.TransferOut
+			line.Adjustment = prev.Adjustment
+		}
+		lines = append(lines, line)
+	}
+
+	for id, prev := range existing {
+		if (prev.TransferIn != 0 || prev.TransferOut != 0) && !containsProduct(lines, id) {
+			return nil, &lineError{"sku " + prev.SKU + " has stock transfers on this day; keep its line"}
+		}
+		if prev.Adjustment != 0 && !containsProduct(lines, id) {
+			return nil, &lineError{"sku " + prev.SKU + " has stock adjustments on this day; keep its line"}
+		}
+	}
+	return lines, nil
+}
+
+// lineBalances returns each product's Physical in a store as of its most
+// recent log dated before from; the openings its next lines carry forward.
+func (sc *SalesController) lineBalances(sctx mongo.SessionContext, workspace, store primitive.ObjectID, from interface{}) (map[primitive.ObjectID]int, error) {
+	cursor, err := sc.SalesColl.Aggregate(sctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"workspace_id": workspace, "store_id": store, "date": bson.M{"$lt": from}, "lines.0": bson.M{"$exists": true}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "date", Value: -1}}}},
+		{{Key: "$unwind", Value: "$lines"}},
+		{{Key: "$group", Value: bson.M{"_id": "$lines.product_id", "physical": bson.M{"$first": "$lines.physical"}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ProductID primitive.ObjectID `bson:"_id"`
+		Physical  int                `bson:"physical"`
+	}
+	if err := cursor.All(sctx, &rows); err != nil {
+		return nil, err
+	}
+
+	balances := make(map[primitive.ObjectID]int, len(rows))
+	for _, r := range rows {
+		balances[r.ProductID] = r.Physical
+	}
+	return balances, nil
+}
+
+// rollUp sets a log's shop-level figures to the totals of its lines.
+func rollUp(log *models.SalesLog) {
+	log.Opening, log.Inward, log.Sales, log.Outward = 0, 0, 0, 0
+	log.TransferIn, log.TransferOut, log.Adjustment = 0, 0, 0
+	log.Physical, log.System, log.Difference = 0, 0, 0
+	for _, l := range log.Lines {
+		log.Opening += l.Opening
+		log.Inward += l.Inward
+		log.Sales += l.Sales
+		log.Outward += l.Outward
+		log.TransferIn += l.TransferIn
+		log.TransferOut += l.TransferOut
+		log.Adjustment += l.Adjustment
+		log.Physical += l.Physical
+		log.System += l.System
+		log.Difference += l.Difference
+	}
+}
+
+// linesByProduct indexes a log's stored lines by product.
+func linesByProduct(log models.SalesLog) map[primitive.ObjectID]models.SalesLogLine {
+	lines := make(map[primitive.ObjectID]models.SalesLogLine, len(log.Lines))
+	for _, l := range log.Lines {
+		lines[l.ProductID] = l
+	}
+	return lines
+}
+
+func containsProduct(lines []models.SalesLogLine, id primitive.ObjectID) bool {
+	for _, l := range lines {
+		if l.ProductID == id {
+			return true
+		}
+	}
+	return false
+}
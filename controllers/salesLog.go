@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -12,24 +14,52 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SalesController struct {
-	SalesColl *mongo.Collection
+	SalesColl      *mongo.Collection
+	UserColl       *mongo.Collection
+	HolidayColl    *mongo.Collection
+	ProductColl    *mongo.Collection
+	StoreColl      *mongo.Collection
+	TransferColl   *mongo.Collection
+	AdjustmentColl *mongo.Collection
+	Audit          *Auditor
+	Alerts         *Alerter
 }
 
-func NewSalesController(salesColl *mongo.Collection) *SalesController {
+func NewSalesController(salesColl, userColl, holidayColl, productColl, storeColl, transferColl, adjustmentColl *mongo.Collection, audit *Auditor, alerts *Alerter) *SalesController {
 	return &SalesController{
-		SalesColl: salesColl,
+		SalesColl:      salesColl,
+		UserColl:       userColl,
+		HolidayColl:    holidayColl,
+		ProductColl:    productColl,
+		StoreColl:      storeColl,
+		TransferColl:   transferColl,
+		AdjustmentColl: adjustmentColl,
+		Audit:          audit,
+		Alerts:         alerts,
 	}
 }
 
 // GetSalesLogs → GET /salesLogs
+// Returns every store's logs unless narrowed with store_id.
 func (sc *SalesController) GetSalesLogs(ctx iris.Context) {
+	filter := scoped(ctx, bson.M{})
+	if v := ctx.URLParam("store_id"); v != "" {
+		storeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid store_id", nil)
+			return
+		}
+		filter["store_id"] = storeID
+	}
+
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := sc.SalesColl.Find(timeoutCtx, bson.M{})
+	cursor, err := sc.SalesColl.Find(timeoutCtx, filter)
 	if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch sales-logs: "+err.Error(), nil)
 		return
@@ -44,6 +74,11 @@ func (sc *SalesController) GetSalesLogs(ctx iris.Context) {
 		}
 	}
 
+	if err := sc.expandUsers(ctx, timeoutCtx, salesLogs); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
+		return
+	}
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched sales-logs successfully!", salesLogs)
 }
 
@@ -62,7 +97,7 @@ func (sc *SalesController) GetSalesLogByID(ctx iris.Context) {
 	}
 
 	var salesLog models.SalesLog
-	err = sc.SalesColl.FindOne(timeoutCtx, bson.M{"_id": objectID}).Decode(&salesLog)
+	err = sc.SalesColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&salesLog)
 	if err == mongo.ErrNoDocuments {
 		utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
 		return
@@ -71,74 +106,180 @@ func (sc *SalesController) GetSalesLogByID(ctx iris.Context) {
 		return
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched sales-log successfully!", salesLog)
+	salesLogs := []models.SalesLog{salesLog}
+	if err := sc.expandUsers(ctx, timeoutCtx, salesLogs); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Fetched sales-log successfully!", salesLogs[0])
+}
+
+// expandUsers fills in the names of who created and last updated each log.
+func (sc *SalesController) expandUsers(ctx iris.Context, cctx context.Context, salesLogs []models.SalesLog) error {
+	ids := make([]primitive.ObjectID, 0, 2*len(salesLogs))
+	for _, s := range salesLogs {
+		ids = append(ids, s.CreatedBy, s.UpdatedBy)
+	}
+	refs, err := userRefs(ctx, cctx, sc.UserColl, ids...)
+	if err != nil {
+		return err
+	}
+	for i := range salesLogs {
+		salesLogs[i].CreatedByUser = refs[salesLogs[i].CreatedBy]
+		salesLogs[i].UpdatedByUser = refs[salesLogs[i].UpdatedBy]
+	}
+	return nil
 }
 
 // AddSalesLog → POST /salesLogs
+// Logs dated before existing ones are allowed; every later log is
+// recomputed and returned in "affected". Opening carries forward from the
+// most recent earlier log, and "skipped_days" lists the open days between
+// the two that have no log. The posted timestamp is filed under the business
+// day it falls on in BUSINESS_TIMEZONE. With "lines", stock is tracked per
+// SKU and the top-level figures in the body are ignored. Each store keeps its
+// own chain; store_id defaults to the workspace's default store. Alerts
+// raised by the new log or the recomputed ones are returned in "alerts".
 func (sc *SalesController) AddSalesLog(ctx iris.Context) {
 	var salesLog models.SalesLogRequest
 	if err := ctx.ReadJSON(&salesLog); err != nil {
 		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
 		return
 	}
+	if salesLog.Date.IsZero() {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "date is required", nil)
+		return
+	}
+	day := utils.BusinessDay(salesLog.Date)
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	count, err := sc.SalesColl.CountDocuments(timeoutCtx, bson.M{"date": salesLog.Date})
-	if err != nil {
-		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+	store, err := findStore(timeoutCtx, sc.StoreColl, workspaceID(ctx), salesLog.StoreID)
+	if errors.Is(err, errUnknownStore) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown store_id", nil)
 		return
-	}
-	if count > 0 {
-		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "Date already added!", nil)
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
 		return
 	}
 
-	startOfPrevDay := time.Date(salesLog.Date.Year(), salesLog.Date.Month(), salesLog.Date.Day()-1, 0, 0, 0, 0, salesLog.Date.Location())
-	endOfPrevDay := startOfPrevDay.Add(24 * time.Hour)
-
-	filter := bson.M{
-		"date": bson.M{
-			"$gte": startOfPrevDay,
-			"$lt":  endOfPrevDay,
-		},
+	insert := models.SalesLog{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspaceID(ctx),
+		StoreID:     store.ID,
+		Date:        day,
+		Inward:      salesLog.Inward,
+		Sales:       salesLog.Sales,
+		Outward:     salesLog.Outward,
+		System:      salesLog.System,
+		CreatedBy:   currentUserID(ctx),
+		UpdatedBy:   currentUserID(ctx),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
-	var prevSalesLog models.SalesLog
-	err = sc.SalesColl.FindOne(timeoutCtx, filter).Decode(&prevSalesLog)
-	if err != nil && err != mongo.ErrNoDocuments {
-		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch previous day log: "+err.Error(), nil)
-		return
+	if len(salesLog.Lines) > 0 {
+		lines, err := sc.resolveLines(timeoutCtx, insert.WorkspaceID, salesLog.Lines, nil)
+		var lineErr *lineError
+		if errors.As(err, &lineErr) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", lineErr.Error(), nil)
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch products: "+err.Error(), nil)
+			return
+		}
+		insert.Lines = lines
+		rollUp(&insert)
 	}
 
-	physical := prevSalesLog.Physical + salesLog.Inward - salesLog.Outward - salesLog.Sales
+	var updates []chainUpdate
+	var prevSalesLog *models.SalesLog
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		count, err := sc.SalesColl.CountDocuments(sctx, scoped(ctx, bson.M{
+			"store_id": store.ID,
+			"date":     bson.M{"$gte": day, "$lt": day.AddDate(0, 0, 1)},
+		}))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errDuplicateDay
+		}
 
-	difference := salesLog.System - prevSalesLog.Physical
+		var prev models.SalesLog
+		err = sc.SalesColl.FindOne(sctx,
+			scoped(ctx, bson.M{"store_id": store.ID, "date": bson.M{"$lt": day}}),
+			options.FindOne().SetSort(bson.D{{Key: "date", Value: -1}}),
+		).Decode(&prev)
+		if err == nil {
+			prevSalesLog = &prev
+		} else if err != mongo.ErrNoDocuments {
+			return err
+		}
 
-	insert := models.SalesLog{
-		Date:       salesLog.Date,
-		Opening:    prevSalesLog.Physical,
-		Inward:     salesLog.Inward,
-		Sales:      salesLog.Sales,
-		Outward:    salesLog.Outward,
-		Physical:   physical,
-		System:     salesLog.System,
-		Difference: difference,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	}
-
-	result, err := sc.SalesColl.InsertOne(timeoutCtx, insert)
-	if err != nil {
+		// The unique index on (workspace_id, store_id, date) catches a
+		// concurrent insert the count above can't see.
+		if _, err := sc.SalesColl.InsertOne(sctx, insert); mongo.IsDuplicateKeyError(err) {
+			return errDuplicateDay
+		} else if err != nil {
+			return err
+		}
+
+		updates, err = sc.recomputeChain(sctx, insert.WorkspaceID, insert.StoreID, insert.Date)
+		return err
+	})
+	if errors.Is(err, errDuplicateDay) {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "Date already added for "+store.Name+"!", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert sales-logs: "+err.Error(), nil)
 		return
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Sales log created successfully!", iris.Map{"inserted_id": result.InsertedID})
+	written := []models.SalesLog{}
+	for _, u := range updates {
+		if u.After.ID == insert.ID {
+			insert = u.After
+		} else {
+			sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+			written = append(written, u.After)
+		}
+	}
+	sc.Audit.Record(ctx, models.AuditCreate, sc.SalesColl.Name(), insert.ID, nil, insert)
+	alerts := sc.Alerts.Evaluate(ctx, append([]models.SalesLog{insert}, written...)...)
+
+	skipped := []time.Time{}
+	if prevSalesLog != nil {
+		cal, err := loadHolidayCalendar(timeoutCtx, sc.HolidayColl, insert.WorkspaceID)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch holidays: "+err.Error(), nil)
+			return
+		}
+		skipped = missingDays(utils.CalendarDay(prevSalesLog.Date).AddDate(0, 0, 1), day.AddDate(0, 0, -1), nil, cal)
+	}
+
+	message := "Sales log created successfully!"
+	if len(skipped) > 0 {
+		message = fmt.Sprintf("Sales log created, but %d day(s) since %s have no log. Opening was carried forward from that day.",
+			len(skipped), utils.DayKey(prevSalesLog.Date))
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", message, iris.Map{
+		"inserted_id":  insert.ID,
+		"store_id":     insert.StoreID,
+		"affected":     affectedDays(updates),
+		"days_skipped": len(skipped) > 0,
+		"skipped_days": skipped,
+		"alerts":       alerts,
+	})
 }
 
 // UpdateSalesLogByID → PUT /salesLogs/{id}
+// Every later log is recomputed and returned in "affected". Logs that track
+// products must be updated with their full set of lines. Alerts raised by
+// the log or the recomputed ones are returned in "alerts".
 func (sc *SalesController) UpdateSalesLogByID(ctx iris.Context) {
 	id := ctx.Params().Get("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -153,67 +294,78 @@ func (sc *SalesController) UpdateSalesLogByID(ctx iris.Context) {
 		return
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	startOfPrevDay := time.Date(updateData.Date.Year(), updateData.Date.Month(), updateData.Date.Day()-1, 0, 0, 0, 0, updateData.Date.Location())
-	endOfPrevDay := startOfPrevDay.Add(24 * time.Hour)
-
-	filter := bson.M{
-		"date": bson.M{
-			"$gte": startOfPrevDay,
-			"$lt":  endOfPrevDay,
-		},
-	}
-
-	var prevSalesLog models.SalesLog
-	err = sc.SalesColl.FindOne(ctxTimeout, filter).Decode(&prevSalesLog)
-	if err != nil && err != mongo.ErrNoDocuments {
-		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch previous day log: "+err.Error(), nil)
-		return
-	}
-
 	var currentSalesLog models.SalesLog
-	err = sc.SalesColl.FindOne(ctxTimeout, bson.M{"_id": objectID}).Decode(&currentSalesLog)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
-			return
+	var updates []chainUpdate
+	err = sc.inTransaction(ctxTimeout, func(sctx mongo.SessionContext) error {
+		err := sc.SalesColl.FindOne(sctx, scoped(ctx, bson.M{"_id": objectID})).Decode(&currentSalesLog)
+		if err != nil {
+			return err
 		}
-		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch current log: "+err.Error(), nil)
-		return
-	}
-
-	physical := prevSalesLog.Physical + updateData.Inward - updateData.Outward - updateData.Sales
-
-	difference := currentSalesLog.System - prevSalesLog.Physical
 
-	update := bson.M{
-		"$set": bson.M{
+		set := bson.M{
 			"inward":     updateData.Inward,
 			"sales":      updateData.Sales,
 			"outward":    updateData.Outward,
-			"physical":   physical,
-			"difference": difference,
+			"updated_by": currentUserID(ctx),
 			"updated_at": time.Now(),
-		},
-	}
+		}
+		switch {
+		case len(updateData.Lines) > 0:
+			lines, err := sc.resolveLines(sctx, currentSalesLog.WorkspaceID, updateData.Lines, linesByProduct(currentSalesLog))
+			if err != nil {
+				return err
+			}
+			// Totals are rolled up from the lines by recomputeChain.
+			set = bson.M{"lines": lines, "updated_by": currentUserID(ctx), "updated_at": time.Now()}
+		case len(currentSalesLog.Lines) > 0:
+			return &lineError{"this log tracks products; send its lines"}
+		}
 
-	res, err := sc.SalesColl.UpdateByID(ctxTimeout, objectID, update)
-	if err != nil {
+		if _, err := sc.SalesColl.UpdateByID(sctx, objectID, bson.M{"$set": set}); err != nil {
+			return err
+		}
+
+		updates, err = sc.recomputeChain(sctx, currentSalesLog.WorkspaceID, currentSalesLog.StoreID, currentSalesLog.Date)
+		return err
+	})
+	var lineErr *lineError
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
+		return
+	} else if errors.As(err, &lineErr) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", lineErr.Error(), nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
 
-	if res.MatchedCount == 0 {
-		utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
-		return
+	written := []models.SalesLog{}
+	var updatedSalesLog models.SalesLog
+	if err := sc.SalesColl.FindOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&updatedSalesLog); err == nil {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), objectID, currentSalesLog, updatedSalesLog)
+		written = append(written, updatedSalesLog)
+	}
+	for _, u := range updates {
+		if u.After.ID != objectID {
+			sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+			written = append(written, u.After)
+		}
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Updated successfully", iris.Map{"id": objectID})
+	utils.SendResponse(ctx, http.StatusOK, "success", "Updated successfully", iris.Map{
+		"id":       objectID,
+		"affected": affectedDays(updates),
+		"alerts":   sc.Alerts.Evaluate(ctx, written...),
+	})
 }
 
 // DeleteSalesLogByID → DELETE /salesLogs/{id}
+// Every later log is recomputed and returned in "affected". Logs with stock
+// transfers or approved adjustments can't be deleted until those are.
 func (sc *SalesController) DeleteSalesLogByID(ctx iris.Context) {
 	id := ctx.Params().Get("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -222,19 +374,61 @@ func (sc *SalesController) DeleteSalesLogByID(ctx iris.Context) {
 		return
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	res, err := sc.SalesColl.DeleteOne(ctxTimeout, bson.M{"_id": objectID})
-	if err != nil {
+	var deleted models.SalesLog
+	var updates []chainUpdate
+	err = sc.inTransaction(ctxTimeout, func(sctx mongo.SessionContext) error {
+		transfers, err := sc.TransferColl.CountDocuments(sctx, scoped(ctx, bson.M{
+			"$or": []bson.M{{"from_log_id": objectID}, {"to_log_id": objectID}},
+		}))
+		if err != nil {
+			return err
+		}
+		if transfers > 0 {
+			return errLogHasTransfers
+		}
+		adjustments, err := sc.AdjustmentColl.CountDocuments(sctx, scoped(ctx, bson.M{
+			"sales_log_id": objectID,
+			"status":       models.AdjustmentApproved,
+		}))
+		if err != nil {
+			return err
+		}
+		if adjustments > 0 {
+			return errLogHasAdjustments
+		}
+
+		err = sc.SalesColl.FindOneAndDelete(sctx, scoped(ctx, bson.M{"_id": objectID})).Decode(&deleted)
+		if err != nil {
+			return err
+		}
+
+		updates, err = sc.recomputeChain(sctx, deleted.WorkspaceID, deleted.StoreID, deleted.Date)
+		return err
+	})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
+		return
+	} else if errors.Is(err, errLogHasTransfers) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "Sales log has stock transfers; delete them first", nil)
+		return
+	} else if errors.Is(err, errLogHasAdjustments) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "Sales log has approved stock adjustments; delete them first", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
 
-	if res.DeletedCount == 0 {
-		utils.SendResponse(ctx, http.StatusNotFound, "error", "Sales log not found", nil)
-		return
+	sc.Audit.Record(ctx, models.AuditDelete, sc.SalesColl.Name(), objectID, deleted, nil)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "Deleted successfully", iris.Map{"id": objectID})
+	utils.SendResponse(ctx, http.StatusOK, "success", "Deleted successfully", iris.Map{
+		"id":       objectID,
+		"affected": affectedDays(updates),
+	})
 }
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSessions → GET /auth/sessions
+func (ac *AuthController) GetSessions(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := ac.SessionColl.Find(timeoutCtx,
+		bson.M{
+			"user_id":    currentUserID(ctx),
+			"revoked_at": bson.M{"$exists": false},
+			"expires_at": bson.M{"$gt": time.Now()},
+		},
+		options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch sessions: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	var sessions []models.Session
+	if err := cursor.All(timeoutCtx, &sessions); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode sessions: "+err.Error(), nil)
+		return
+	}
+
+	current := currentSessionID(ctx)
+	items := make([]iris.Map, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, iris.Map{
+			"id":           s.ID,
+			"user_agent":   s.UserAgent,
+			"ip":           s.IP,
+			"created_at":   s.CreatedAt,
+			"last_seen_at": s.LastSeenAt,
+			"current":      s.ID == current,
+		})
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Sessions fetched successfully!", items)
+}
+
+// RevokeSessionByID → DELETE /auth/sessions/{id}
+func (ac *AuthController) RevokeSessionByID(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session models.Session
+	err = ac.SessionColl.FindOne(timeoutCtx, bson.M{
+		"_id":        objectID,
+		"user_id":    currentUserID(ctx),
+		"revoked_at": bson.M{"$exists": false},
+	}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Session not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	if err := ac.revokeSession(timeoutCtx, session.ID); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Session signed out successfully!", iris.Map{"id": objectID})
+}
+
+// RevokeAllSessions → DELETE /auth/sessions
+// Signs the user out everywhere. With ?except_current=true the session
+// making the request survives.
+func (ac *AuthController) RevokeAllSessions(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	except := primitive.NilObjectID
+	if ctx.URLParamBoolDefault("except_current", false) {
+		except = currentSessionID(ctx)
+	}
+
+	count, err := ac.revokeUserSessions(timeoutCtx, currentUserID(ctx), except)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Signed out of all sessions!", iris.Map{"revoked": count})
+}
+
+// revokeSession ends one session: its refresh tokens stop rotating and
+// AuthMiddleware rejects access tokens already issued for it.
+func (ac *AuthController) revokeSession(cctx context.Context, sessionID primitive.ObjectID) error {
+	now := time.Now().UTC()
+
+	_, err := ac.RefreshColl.UpdateMany(cctx,
+		bson.M{"family_id": sessionID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ac.SessionColl.UpdateOne(cctx,
+		bson.M{"_id": sessionID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Every access token of this session is dead within one access TTL, so
+	// the block entry only needs to live that long.
+	key := sessionRevocationKey(sessionID)
+	_, err = ac.RevokedColl.UpdateByID(cctx, key, bson.M{
+		"$set": models.RevokedToken{
+			JTI:       key,
+			ExpiresAt: now.Add(ac.AccessTokenTTL),
+			RevokedAt: now,
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// revokeUserSessions ends every session of userID other than except and
+// returns how many were ended.
+func (ac *AuthController) revokeUserSessions(cctx context.Context, userID, except primitive.ObjectID) (int, error) {
+	ids, err := ac.SessionColl.Distinct(cctx, "_id", bson.M{
+		"user_id":    userID,
+		"_id":        bson.M{"$ne": except},
+		"revoked_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			if err := ac.revokeSession(cctx, oid); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// Refresh tokens issued before sessions were tracked have no session
+	// document; make sure they die too.
+	_, err = ac.RefreshColl.UpdateMany(cctx,
+		bson.M{"user_id": userID, "family_id": bson.M{"$ne": except}, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}},
+	)
+	return len(ids), err
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultStoreName names the store created for a workspace the first time
+// a log is filed without a store.
+const defaultStoreName = "Main store"
+
+var errUnknownStore = errors.New("store not found")
+
+type StoreController struct {
+	StoreColl *mongo.Collection
+	SalesColl *mongo.Collection
+	Audit     *Auditor
+}
+
+func NewStoreController(storeColl, salesColl *mongo.Collection, audit *Auditor) *StoreController {
+	return &StoreController{
+		StoreColl: storeColl,
+		SalesColl: salesColl,
+		Audit:     audit,
+	}
+}
+
+// GetStores → GET /stores
+func (stc *StoreController) GetStores(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := stc.StoreColl.Find(timeoutCtx, scoped(ctx, bson.M{}),
+		options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch stores: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	stores := []models.Store{}
+	if err := cursor.All(timeoutCtx, &stores); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode stores: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Stores fetched successfully!", stores)
+}
+
+// AddStore → POST /stores
+// The first store of a workspace becomes its default.
+func (stc *StoreController) AddStore(ctx iris.Context) {
+	var req models.StoreRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "name is required", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := stc.StoreColl.CountDocuments(timeoutCtx, scoped(ctx, bson.M{}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	store := models.Store{
+		WorkspaceID: workspaceID(ctx),
+		Name:        req.Name,
+		Address:     strings.TrimSpace(req.Address),
+		IsDefault:   req.IsDefault || count == 0,
+		CreatedBy:   currentUserID(ctx),
+		UpdatedBy:   currentUserID(ctx),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if store.IsDefault {
+		if err := stc.clearDefault(ctx, timeoutCtx); err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+			return
+		}
+	}
+
+	result, err := stc.StoreColl.InsertOne(timeoutCtx, store)
+	if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "A store with that name already exists", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert store: "+err.Error(), nil)
+		return
+	}
+	store.ID = result.InsertedID.(primitive.ObjectID)
+
+	stc.Audit.Record(ctx, models.AuditCreate, stc.StoreColl.Name(), store.ID, nil, store)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Store created successfully!", store)
+}
+
+// UpdateStoreByID → PUT /stores/{id}
+// Setting is_default moves the default here; the default can't be unset
+// directly, only by making another store the default.
+func (stc *StoreController) UpdateStoreByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	var req models.StoreRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "name is required", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var previous models.Store
+	if err := stc.StoreColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&previous); err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Store not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	set := bson.M{
+		"name":       req.Name,
+		"address":    strings.TrimSpace(req.Address),
+		"updated_by": currentUserID(ctx),
+		"updated_at": time.Now().UTC(),
+	}
+	if req.IsDefault && !previous.IsDefault {
+		if err := stc.clearDefault(ctx, timeoutCtx); err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+			return
+		}
+		set["is_default"] = true
+	}
+
+	var store models.Store
+	err = stc.StoreColl.FindOneAndUpdate(timeoutCtx,
+		scoped(ctx, bson.M{"_id": objectID}),
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&store)
+	if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "A store with that name already exists", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to update store: "+err.Error(), nil)
+		return
+	}
+
+	stc.Audit.Record(ctx, models.AuditUpdate, stc.StoreColl.Name(), objectID, previous, store)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Store updated successfully!", store)
+}
+
+// DeleteStoreByID → DELETE /stores/{id}
+// Stores with sales logs, and the default store, can't be deleted.
+func (stc *StoreController) DeleteStoreByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	used, err := stc.SalesColl.CountDocuments(timeoutCtx, scoped(ctx, bson.M{"store_id": objectID}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+	if used > 0 {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "Store has sales logs and can't be deleted", nil)
+		return
+	}
+
+	var deleted models.Store
+	err = stc.StoreColl.FindOneAndDelete(timeoutCtx, scoped(ctx, bson.M{"_id": objectID, "is_default": false})).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Store not found, or it is the default store", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	stc.Audit.Record(ctx, models.AuditDelete, stc.StoreColl.Name(), objectID, deleted, nil)
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Store deleted successfully!", iris.Map{"id": objectID})
+}
+
+// clearDefault unsets the workspace's current default store.
+func (stc *StoreController) clearDefault(ctx iris.Context, cctx context.Context) error {
+	_, err := stc.StoreColl.UpdateMany(cctx, scoped(ctx, bson.M{"is_default": true}), bson.M{
+		"$set": bson.M{"is_default": false, "updated_by": currentUserID(ctx), "updated_at": time.Now().UTC()},
+	})
+	return err
+}
+
+// findStore loads the store with the given hex ID, or the workspace's default
+// store when id is empty, creating the default if the workspace has none.
+func findStore(cctx context.Context, storeColl *mongo.Collection, workspace primitive.ObjectID, id string) (models.Store, error) {
+	var store models.Store
+	if id == "" {
+		now := time.Now().UTC()
+		err := storeColl.FindOneAndUpdate(cctx,
+			bson.M{"workspace_id": workspace, "is_default": true},
+			bson.M{"$setOnInsert": bson.M{"name": defaultStoreName, "created_at": now, "updated_at": now}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&store)
+		return store, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return store, errUnknownStore
+	}
+	err = storeColl.FindOne(cctx, bson.M{"_id": objectID, "workspace_id": workspace}).Decode(&store)
+	if err == mongo.ErrNoDocuments {
+		return store, errUnknownStore
+	}
+	return store, err
+}
+
+// storeNames maps the workspace's store IDs to their names.
+func storeNames(cctx context.Context, storeColl *mongo.Collection, workspace primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	cursor, err := storeColl.Find(cctx, bson.M{"workspace_id": workspace}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var stores []models.Store
+	if err := cursor.All(cctx, &stores); err != nil {
+		return nil, err
+	}
+
+	names := make(map[primitive.ObjectID]string, len(stores))
+	for _, s := range stores {
+		names[s.ID] = s.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ThrottlePolicy describes how quickly a key is slowed down. The first
+// FreeAttempts failures cost nothing; after that each failure doubles the
+// wait starting at BaseDelay, capped at Lockout.
+type ThrottlePolicy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	Lockout      time.Duration
+	Window       time.Duration // failures are forgotten after this much quiet
+}
+
+// LoginThrottler persists failed-login counters in Mongo so lockouts survive
+// restarts of the Fly machine.
+type LoginThrottler struct {
+	Coll          *mongo.Collection
+	AccountPolicy ThrottlePolicy
+	IPPolicy      ThrottlePolicy
+}
+
+func NewLoginThrottler(throttleColl *mongo.Collection) *LoginThrottler {
+	return &LoginThrottler{
+		Coll: throttleColl,
+		// 5 free tries, then 1s, 2s, 4s, … until the wait reaches a 15 minute lockout.
+		AccountPolicy: ThrottlePolicy{FreeAttempts: 5, BaseDelay: time.Second, Lockout: 15 * time.Minute, Window: 24 * time.Hour},
+		// An IP can be shared by a whole household, so it gets more slack.
+		IPPolicy: ThrottlePolicy{FreeAttempts: 20, BaseDelay: time.Second, Lockout: 15 * time.Minute, Window: time.Hour},
+	}
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// RetryAfter returns how long the caller must still wait before another
+// attempt for any of keys is allowed, or zero.
+func (lt *LoginThrottler) RetryAfter(cctx context.Context, keys ...string) (time.Duration, error) {
+	cursor, err := lt.Coll.Find(cctx, bson.M{
+		"_id":          bson.M{"$in": keys},
+		"locked_until": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(cctx)
+
+	var wait time.Duration
+	for cursor.Next(cctx) {
+		var t models.LoginThrottle
+		if err := cursor.Decode(&t); err == nil {
+			if d := time.Until(t.LockedUntil); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait, cursor.Err()
+}
+
+// Fail records a failed attempt for key and applies the policy's backoff.
+func (lt *LoginThrottler) Fail(cctx context.Context, key string, policy ThrottlePolicy) error {
+	now := time.Now()
+
+	var t models.LoginThrottle
+	err := lt.Coll.FindOneAndUpdate(cctx,
+		bson.M{"_id": key},
+		bson.M{
+			"$inc": bson.M{"failures": 1},
+			"$set": bson.M{"last_failure_at": now, "expires_at": now.Add(policy.Window)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&t)
+	if err != nil {
+		return err
+	}
+
+	delay := policy.delay(t.Failures)
+	if delay == 0 {
+		return nil
+	}
+	_, err = lt.Coll.UpdateByID(cctx, key, bson.M{"$max": bson.M{"locked_until": now.Add(delay)}})
+	return err
+}
+
+// Reset clears the counter for key, e.g. after a successful login or an
+// admin unlock.
+func (lt *LoginThrottler) Reset(cctx context.Context, key string) error {
+	_, err := lt.Coll.DeleteOne(cctx, bson.M{"_id": key})
+	return err
+}
+
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	over := failures - p.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	d := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(over-1)))
+	if d <= 0 || d > p.Lockout {
+		d = p.Lockout
+	}
+	return d
+}
+
+// sendTooManyAttempts answers 429 with a Retry-After header in whole seconds.
+func sendTooManyAttempts(ctx iris.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	utils.SendResponseAndStop(ctx, http.StatusTooManyRequests, "error", "Too many failed attempts. Please try again later.", iris.Map{
+		"retry_after": seconds,
+	})
+}
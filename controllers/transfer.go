@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// transferError is a transfer that doesn't fit the logs it would post to,
+// reported as a 409.
+type transferError struct {
+	msg string
+}
+
+func (e *transferError) Error() string { return e.msg }
+
+// GetStockTransfers → GET /transfers
+// Optional filters: store_id (either side), from and to (YYYY-MM-DD).
+func (sc *SalesController) GetStockTransfers(ctx iris.Context) {
+	filter := scoped(ctx, bson.M{})
+	if v := ctx.URLParam("store_id"); v != "" {
+		storeID, err := primitive.ObjectIDFromHex(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid store_id", nil)
+			return
+		}
+		filter["$or"] = []bson.M{{"from_store_id": storeID}, {"to_store_id": storeID}}
+	}
+	dates := bson.M{}
+	if v := ctx.URLParam("from"); v != "" {
+		from, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "from must be in YYYY-MM-DD format", nil)
+			return
+		}
+		dates["$gte"] = from
+	}
+	if v := ctx.URLParam("to"); v != "" {
+		to, err := utils.ParseDay(v)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", "to must be in YYYY-MM-DD format", nil)
+			return
+		}
+		dates["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(dates) > 0 {
+		filter["date"] = dates
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := sc.TransferColl.Find(timeoutCtx, filter, options.Find().SetSort(bson.D{{Key: "date", Value: -1}}))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch transfers: "+err.Error(), nil)
+		return
+	}
+	defer cursor.Close(timeoutCtx)
+
+	transfers := []models.StockTransfer{}
+	if err := cursor.All(timeoutCtx, &transfers); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to decode transfers: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Transfers fetched successfully!", transfers)
+}
+
+// AddStockTransfer → POST /transfers
+// Posts transfer_out on the source store's log for the day and transfer_in
+// on the destination's, then recomputes both chains. Both stores need a log
+// for the day already.
+func (sc *SalesController) AddStockTransfer(ctx iris.Context) {
+	var req models.StockTransferRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+	if req.FromStoreID == "" || req.ToStoreID == "" || req.Date.IsZero() {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "from_store_id, to_store_id and date are required", nil)
+		return
+	}
+	if req.FromStoreID == req.ToStoreID {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "A store can't transfer to itself", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace := workspaceID(ctx)
+	var toStore models.Store
+	fromStore, err := findStore(timeoutCtx, sc.StoreColl, workspace, req.FromStoreID)
+	if err == nil {
+		toStore, err = findStore(timeoutCtx, sc.StoreColl, workspace, req.ToStoreID)
+	}
+	if errors.Is(err, errUnknownStore) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Unknown from_store_id or to_store_id", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch store: "+err.Error(), nil)
+		return
+	}
+	transfer := models.StockTransfer{
+		ID:          primitive.NewObjectID(),
+		WorkspaceID: workspace,
+		Date:        utils.BusinessDay(req.Date),
+		FromStoreID: fromStore.ID,
+		ToStoreID:   toStore.ID,
+		Quantity:    req.Quantity,
+		Note:        strings.TrimSpace(req.Note),
+		CreatedBy:   currentUserID(ctx),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if len(req.Lines) > 0 {
+		transfer.Lines, err = sc.resolveTransferLines(timeoutCtx, workspace, req.Lines)
+		var lineErr *lineError
+		if errors.As(err, &lineErr) {
+			utils.SendResponse(ctx, http.StatusBadRequest, "error", lineErr.Error(), nil)
+			return
+		} else if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch products: "+err.Error(), nil)
+			return
+		}
+		transfer.Quantity = 0
+		for _, l := range transfer.Lines {
+			transfer.Quantity += l.Quantity
+		}
+	}
+	if transfer.Quantity <= 0 {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "quantity must be positive", nil)
+		return
+	}
+
+	var updates []chainUpdate
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		var err error
+		if transfer.FromLogID, err = sc.postTransfer(sctx, transfer, transfer.FromStoreID, 1); err != nil {
+			return err
+		}
+		if transfer.ToLogID, err = sc.postTransfer(sctx, transfer, transfer.ToStoreID, 1); err != nil {
+			return err
+		}
+		if _, err := sc.TransferColl.InsertOne(sctx, transfer); err != nil {
+			return err
+		}
+		updates, err = sc.recomputeStores(sctx, transfer)
+		return err
+	})
+	var transferErr *transferError
+	if errors.As(err, &transferErr) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", transferErr.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to post transfer: "+err.Error(), nil)
+		return
+	}
+
+	sc.Audit.Record(ctx, models.AuditCreate, sc.TransferColl.Name(), transfer.ID, nil, transfer)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Transfer posted successfully!", iris.Map{
+		"transfer": transfer,
+		"affected": affectedDays(updates),
+	})
+}
+
+// DeleteStockTransferByID → DELETE /transfers/{id}
+// Reverses both sides of the transfer and recomputes both chains.
+func (sc *SalesController) DeleteStockTransferByID(ctx iris.Context) {
+	objectID, err := primitive.ObjectIDFromHex(ctx.Params().Get("id"))
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var deleted models.StockTransfer
+	var updates []chainUpdate
+	err = sc.inTransaction(timeoutCtx, func(sctx mongo.SessionContext) error {
+		err := sc.TransferColl.FindOneAndDelete(sctx, scoped(ctx, bson.M{"_id": objectID})).Decode(&deleted)
+		if err != nil {
+			return err
+		}
+		if _, err := sc.postTransfer(sctx, deleted, deleted.FromStoreID, -1); err != nil {
+			return err
+		}
+		if _, err := sc.postTransfer(sctx, deleted, deleted.ToStoreID, -1); err != nil {
+			return err
+		}
+		updates, err = sc.recomputeStores(sctx, deleted)
+		return err
+	})
+	var transferErr *transferError
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Transfer not found", nil)
+		return
+	} else if errors.As(err, &transferErr) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", transferErr.Error(), nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	sc.Audit.Record(ctx, models.AuditDelete, sc.TransferColl.Name(), objectID, deleted, nil)
+	for _, u := range updates {
+		sc.Audit.Record(ctx, models.AuditUpdate, sc.SalesColl.Name(), u.After.ID, u.Before, u.After)
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Transfer deleted successfully!", iris.Map{
+		"id":       objectID,
+		"affected": affectedDays(updates),
+	})
+}
+
+// postTransfer adds sign times the transfer to the given store's log for the
+// transfer's day, as transfer_out on the source and transfer_in on the
+// destination. It returns the log's ID.
+func (sc *SalesController) postTransfer(sctx mongo.SessionContext, t models.StockTransfer, store primitive.ObjectID, sign int) (primitive.ObjectID, error) {
+	var log models.SalesLog
+	err := sc.SalesColl.FindOne(sctx, bson.M{
+		"workspace_id": t.WorkspaceID,
+		"store_id":     store,
+		"date":         bson.M{"$gte": t.Date, "$lt": t.Date.AddDate(0, 0, 1)},
+	}).Decode(&log)
+	if err == mongo.ErrNoDocuments {
+		return log.ID, &transferError{"Both stores need a sales log for " + utils.DayKey(t.Date) + " before stock can be transferred"}
+	} else if err != nil {
+		return log.ID, err
+	}
+
+	outgoing := store == t.FromStoreID
+	move := func(in, out *int, qty int) {
+		if outgoing {
+			*out += sign * qty
+		} else {
+			*in += sign * qty
+		}
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	switch {
+	case len(log.Lines) > 0 && len(t.Lines) == 0:
+		return log.ID, &transferError{"The sales logs track products; transfer lines instead of a quantity"}
+	case len(log.Lines) == 0 && len(t.Lines) > 0:
+		return log.ID, &transferError{"The sales logs don't track products; transfer a quantity instead of lines"}
+	case len(t.Lines) > 0:
+		for _, tl := range t.Lines {
+			i := 0
+			for i < len(log.Lines) && log.Lines[i].ProductID != tl.ProductID {
+				i++
+			}
+			if i == len(log.Lines) {
+				log.Lines = append(log.Lines, models.SalesLogLine{ProductID: tl.ProductID, SKU: tl.SKU})
+			}
+			move(&log.Lines[i].TransferIn, &log.Lines[i].TransferOut, tl.Quantity)
+		}
+		set["lines"] = log.Lines
+	default:
+		move(&log.TransferIn, &log.TransferOut, t.Quantity)
+		set["transfer_in"] = log.TransferIn
+		set["transfer_out"] = log.TransferOut
+	}
+
+	_, err = sc.SalesColl.UpdateByID(sctx, log.ID, bson.M{"$set": set})
+	return log.ID, err
+}
+
+// recomputeStores recomputes both stores' chains from the transfer's day.
+func (sc *SalesController) recomputeStores(sctx mongo.SessionContext, t models.StockTransfer) ([]chainUpdate, error) {
+	var updates []chainUpdate
+	for _, store := range []primitive.ObjectID{t.FromStoreID, t.ToStoreID} {
+		u, err := sc.recomputeChain(sctx, t.WorkspaceID, store, t.Date)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, u...)
+	}
+	return updates, nil
+}
+
+// resolveTransferLines matches posted transfer lines to catalogue products.
+func (sc *SalesController) resolveTransferLines(cctx context.Context, workspace primitive.ObjectID, reqs []models.TransferLineRequest) ([]models.TransferLine, error) {
+	lineReqs := make([]models.SalesLogLineRequest, len(reqs))
+	for i, r := range reqs {
+		if r.Quantity <= 0 {
+			return nil, &lineError{"every line needs a positive quantity"}
+		}
+		lineReqs[i] = models.SalesLogLineRequest{SKU: r.SKU}
+	}
+
+	resolved, err := sc.resolveLines(cctx, workspace, lineReqs, nil)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]models.TransferLine, len(resolved))
+	for i, l := range resolved {
+		lines[i] = models.TransferLine{ProductID: l.ProductID, SKU: l.SKU, Quantity: reqs[i].Quantity}
+	}
+	return lines, nil
+}
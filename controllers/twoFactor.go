@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	totpIssuer        = "Pai Finance"
+	recoveryCodeCount = 10
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// SetupTwoFactor → POST /auth/2fa/setup
+// Generates a pending secret. It only takes effect once EnableTwoFactor
+// confirms the user's authenticator produces matching codes.
+func (ac *AuthController) SetupTwoFactor(ctx iris.Context) {
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "Two-factor authentication is already enabled", nil)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate secret", nil)
+		return
+	}
+
+	_, err = ac.UserColl.UpdateByID(cctx, user.ID, bson.M{"$set": bson.M{"totp.pending_secret": secret}})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Scan the QR code, then confirm with a code", iris.Map{
+		"secret":           secret,
+		"provisioning_uri": utils.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// EnableTwoFactor → POST /auth/2fa/enable
+func (ac *AuthController) EnableTwoFactor(ctx iris.Context) {
+	var req TwoFactorCodeRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Code == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "code required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		utils.SendResponseAndStop(ctx, http.StatusConflict, "error", "Two-factor authentication is already enabled", nil)
+		return
+	}
+	if user.TOTP.PendingSecret == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Start two-factor setup first", nil)
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TOTP.PendingSecret, req.Code, time.Now())
+	if !valid {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Invalid code", nil)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate recovery codes", nil)
+		return
+	}
+
+	_, err = ac.UserColl.UpdateByID(cctx, user.ID, bson.M{"$set": bson.M{
+		"totp": models.TOTP{
+			Secret:        user.TOTP.PendingSecret,
+			LastStep:      step,
+			RecoveryCodes: hashes,
+		},
+		"totp_enabled": true,
+		"updated_at":   time.Now(),
+	}})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Two-factor authentication enabled! Store these recovery codes somewhere safe.", iris.Map{
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor → POST /auth/2fa/disable
+func (ac *AuthController) DisableTwoFactor(ctx iris.Context) {
+	var req TwoFactorDisableRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Password == "" || req.Code == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "password and code are required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Two-factor authentication is not enabled", nil)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Incorrect password or code", nil)
+		return
+	}
+
+	valid, err := ac.consumeSecondFactor(cctx, user, req.Code)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if !valid {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Incorrect password or code", nil)
+		return
+	}
+
+	_, err = ac.UserColl.UpdateByID(cctx, user.ID, bson.M{
+		"$set":   bson.M{"totp_enabled": false, "updated_at": time.Now()},
+		"$unset": bson.M{"totp": ""},
+	})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes → POST /auth/2fa/recovery-codes
+// Replaces every remaining recovery code with a fresh set.
+func (ac *AuthController) RegenerateRecoveryCodes(ctx iris.Context) {
+	var req TwoFactorCodeRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.Code == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "code required", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	user, ok := ac.loadCurrentUser(ctx, cctx)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "Two-factor authentication is not enabled", nil)
+		return
+	}
+
+	valid, err := ac.consumeSecondFactor(cctx, user, req.Code)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if !valid {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Invalid code", nil)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "failed to generate recovery codes", nil)
+		return
+	}
+
+	_, err = ac.UserColl.UpdateByID(cctx, user.ID, bson.M{"$set": bson.M{"totp.recovery_codes": hashes}})
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Recovery codes regenerated!", iris.Map{
+		"recovery_codes": codes,
+	})
+}
+
+// VerifyTwoFactor → POST /auth/2fa/verify
+// Exchanges the challenge token from Login plus a TOTP or recovery code for
+// the regular access and refresh tokens.
+func (ac *AuthController) VerifyTwoFactor(ctx iris.Context) {
+	var req TwoFactorVerifyRequest
+	if err := ctx.ReadJSON(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		utils.SendResponseAndStop(ctx, http.StatusBadRequest, "error", "challenge_token and code are required", nil)
+		return
+	}
+
+	userID, err := utils.ValidateChallengeToken(req.ChallengeToken)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(ctx.Request().Context(), 6*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := ac.UserColl.FindOne(cctx, bson.M{"_id": userID}).Decode(&user); err != nil || !user.TOTPEnabled {
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Session expired!", nil)
+		return
+	}
+
+	// Codes are only six digits, so guesses share the password lockout.
+	accountKey := accountThrottleKey(user.Email)
+	ipKey := ipThrottleKey(ctx.RemoteAddr())
+
+	wait, err := ac.Throttler.RetryAfter(cctx, accountKey, ipKey)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if wait > 0 {
+		sendTooManyAttempts(ctx, wait)
+		return
+	}
+
+	valid, err := ac.consumeSecondFactor(cctx, user, req.Code)
+	if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+	if !valid {
+		ac.recordLoginFailure(cctx, accountKey, ipKey)
+		utils.SendResponseAndStop(ctx, http.StatusUnauthorized, "error", "Invalid code", nil)
+		return
+	}
+
+	if err := ac.Throttler.Reset(cctx, accountKey); err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return
+	}
+
+	ac.sendLoginSuccess(ctx, cctx, user, "Login successful!")
+}
+
+// consumeSecondFactor accepts either a TOTP code, which must be from a newer
+// time step than the last one used, or an unused recovery code, which is
+// removed. Both checks are atomic updates so a code can't be used twice.
+func (ac *AuthController) consumeSecondFactor(cctx context.Context, user models.User, code string) (bool, error) {
+	if step, ok := utils.ValidateTOTP(user.TOTP.Secret, code, time.Now()); ok {
+		res, err := ac.UserColl.UpdateOne(cctx,
+			bson.M{"_id": user.ID, "totp.last_step": bson.M{"$not": bson.M{"$gte": step}}},
+			bson.M{"$set": bson.M{"totp.last_step": step}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return res.ModifiedCount == 1, nil
+	}
+
+	hash := utils.HashToken(strings.ToLower(strings.TrimSpace(code)))
+	res, err := ac.UserColl.UpdateOne(cctx,
+		bson.M{"_id": user.ID, "totp.recovery_codes": hash},
+		bson.M{"$pull": bson.M{"totp.recovery_codes": hash}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// loadCurrentUser fetches the authenticated user, writing the error response
+// itself when that fails.
+func (ac *AuthController) loadCurrentUser(ctx iris.Context, cctx context.Context) (models.User, bool) {
+	var user models.User
+	err := ac.UserColl.FindOne(cctx, bson.M{"_id": currentUserID(ctx)}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponseAndStop(ctx, http.StatusNotFound, "error", "User not found", nil)
+		return user, false
+	} else if err != nil {
+		utils.SendResponseAndStop(ctx, http.StatusInternalServerError, "error", "database error", nil)
+		return user, false
+	}
+	return user, true
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = utils.HashToken(c)
+	}
+	return codes, hashes, nil
+}
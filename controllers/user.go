@@ -3,6 +3,7 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Ganesh-S-Pai/pai-finance/models"
@@ -15,12 +16,16 @@ import (
 )
 
 type UserController struct {
-	UserColl *mongo.Collection
+	UserColl  *mongo.Collection
+	Throttler *LoginThrottler
+	Audit     *Auditor
 }
 
-func NewUserController(userColl *mongo.Collection) *UserController {
+func NewUserController(userColl *mongo.Collection, throttler *LoginThrottler, audit *Auditor) *UserController {
 	return &UserController{
-		UserColl: userColl,
+		UserColl:  userColl,
+		Throttler: throttler,
+		Audit:     audit,
 	}
 }
 
@@ -32,16 +37,47 @@ func (uc *UserController) AddUser(ctx iris.Context) {
 		return
 	}
 
+	if user.Role == "" {
+		user.Role = models.RoleMember
+	}
+	if !models.IsValidRole(user.Role) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid role", nil)
+		return
+	}
+	if user.Role == models.RoleOwner && ctx.Values().GetString("role") != models.RoleOwner {
+		utils.SendResponse(ctx, http.StatusForbidden, "error", "Only an owner can grant the owner role", nil)
+		return
+	}
+
+	user.WorkspaceID = workspaceID(ctx)
+	user.Email = strings.ToLower(user.Email)
+	user.CreatedBy = currentUserID(ctx)
+	user.UpdatedBy = currentUserID(ctx)
+
 	// Use per-request context with timeout
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if taken, err := uc.emailTaken(timeoutCtx, user.Email, primitive.NilObjectID); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	} else if taken {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	}
+
 	result, err := uc.UserColl.InsertOne(timeoutCtx, user)
-	if err != nil {
+	if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to insert user: "+err.Error(), nil)
 		return
 	}
 
+	insertedID, _ := result.InsertedID.(primitive.ObjectID)
+	uc.Audit.Record(ctx, models.AuditCreate, uc.UserColl.Name(), insertedID, nil, user)
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "User created successfully!", iris.Map{"user_id": result.InsertedID})
 }
 
@@ -50,7 +86,7 @@ func (uc *UserController) GetAllUsers(ctx iris.Context) {
 	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cursor, err := uc.UserColl.Find(timeoutCtx, bson.M{})
+	cursor, err := uc.UserColl.Find(timeoutCtx, scoped(ctx, bson.M{}))
 	if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
 		return
@@ -65,6 +101,11 @@ func (uc *UserController) GetAllUsers(ctx iris.Context) {
 		}
 	}
 
+	if err := uc.expandUsers(ctx, timeoutCtx, users); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
+		return
+	}
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "Users fetched successfully!", users)
 }
 
@@ -82,7 +123,7 @@ func (uc *UserController) GetUserByID(ctx iris.Context) {
 	}
 
 	var user models.UserResponse
-	err = uc.UserColl.FindOne(timeoutCtx, bson.M{"_id": objectID}).Decode(&user)
+	err = uc.UserColl.FindOne(timeoutCtx, scoped(ctx, bson.M{"_id": objectID})).Decode(&user)
 	if err == mongo.ErrNoDocuments {
 		utils.SendResponse(ctx, http.StatusNotFound, "error", "User not found", nil)
 		return
@@ -91,10 +132,35 @@ func (uc *UserController) GetUserByID(ctx iris.Context) {
 		return
 	}
 
-	utils.SendResponse(ctx, http.StatusOK, "success", "User fetched successfully!", user)
+	users := []models.UserResponse{user}
+	if err := uc.expandUsers(ctx, timeoutCtx, users); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Failed to fetch users: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "User fetched successfully!", users[0])
+}
+
+// expandUsers fills in the names of who created and last updated each user.
+func (uc *UserController) expandUsers(ctx iris.Context, cctx context.Context, users []models.UserResponse) error {
+	ids := make([]primitive.ObjectID, 0, 2*len(users))
+	for _, u := range users {
+		ids = append(ids, u.CreatedBy, u.UpdatedBy)
+	}
+	refs, err := userRefs(ctx, cctx, uc.UserColl, ids...)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		users[i].CreatedByUser = refs[users[i].CreatedBy]
+		users[i].UpdatedByUser = refs[users[i].UpdatedBy]
+	}
+	return nil
 }
 
 // UpdateUserByID → PUT /users/{id}
+// Only owners may edit an owner's account, and the last owner of a
+// workspace can't give up the role.
 func (uc *UserController) UpdateUserByID(ctx iris.Context) {
 	id := ctx.Params().Get("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -109,23 +175,83 @@ func (uc *UserController) UpdateUserByID(ctx iris.Context) {
 		return
 	}
 
+	if updateData.Role != "" && !models.IsValidRole(updateData.Role) {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid role", nil)
+		return
+	}
+
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	update := bson.M{
-		"$set": bson.M{
-			"first_name": updateData.FirstName,
-			"last_name":  updateData.LastName,
-			"email":      updateData.Email,
-			"phone":      updateData.Phone,
-			"dob":        updateData.DOB,
-			"gender":     updateData.Gender,
-			"updated_at": time.Now(),
-		},
+	var existing models.User
+	err = uc.UserColl.FindOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "User not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
 	}
 
-	res, err := uc.UserColl.UpdateByID(ctxTimeout, objectID, update)
-	if err != nil {
+	// Changing an owner's email would let an admin reset their password.
+	if existing.Role == models.RoleOwner && ctx.Values().GetString("role") != models.RoleOwner {
+		utils.SendResponse(ctx, http.StatusForbidden, "error", "Only an owner can change an owner's account", nil)
+		return
+	}
+
+	email := strings.ToLower(updateData.Email)
+	set := bson.M{
+		"first_name": updateData.FirstName,
+		"last_name":  updateData.LastName,
+		"email":      email,
+		"phone":      updateData.Phone,
+		"dob":        updateData.DOB,
+		"gender":     updateData.Gender,
+		"updated_by": currentUserID(ctx),
+		"updated_at": time.Now(),
+	}
+
+	// A new address hasn't been proven yet.
+	if email != existing.Email {
+		if taken, err := uc.emailTaken(ctxTimeout, email, existing.ID); err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+			return
+		} else if taken {
+			utils.SendResponse(ctx, http.StatusConflict, "error", "email already registered", nil)
+			return
+		}
+		set["email_verified"] = false
+	}
+
+	if updateData.Role != "" {
+		// Granting or taking away ownership is reserved for owners.
+		if (updateData.Role == models.RoleOwner || existing.Role == models.RoleOwner) &&
+			updateData.Role != existing.Role &&
+			ctx.Values().GetString("role") != models.RoleOwner {
+			utils.SendResponse(ctx, http.StatusForbidden, "error", "Only an owner can change the owner role", nil)
+			return
+		}
+		if existing.Role == models.RoleOwner && updateData.Role != models.RoleOwner {
+			last, err := uc.isLastOwner(ctxTimeout, existing)
+			if err != nil {
+				utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+				return
+			}
+			if last {
+				utils.SendResponse(ctx, http.StatusConflict, "error", "A workspace needs at least one owner", nil)
+				return
+			}
+		}
+		set["role"] = updateData.Role
+	}
+
+	update := bson.M{"$set": set}
+
+	res, err := uc.UserColl.UpdateOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID}), update)
+	if mongo.IsDuplicateKeyError(err) {
+		utils.SendResponse(ctx, http.StatusConflict, "error", "email already registered", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
@@ -136,16 +262,19 @@ func (uc *UserController) UpdateUserByID(ctx iris.Context) {
 	}
 
 	var user models.User
-	err = uc.UserColl.FindOne(ctxTimeout, bson.M{"_id": objectID}).Decode(&user)
+	err = uc.UserColl.FindOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&user)
 	if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
 
+	uc.Audit.Record(ctx, models.AuditUpdate, uc.UserColl.Name(), objectID, existing, user)
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "User updated successfully!", user)
 }
 
 // DeleteUserByID → DELETE /users/{id}
+// Only owners may delete an owner, and never the workspace's last one.
 func (uc *UserController) DeleteUserByID(ctx iris.Context) {
 	id := ctx.Params().Get("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -157,16 +286,92 @@ func (uc *UserController) DeleteUserByID(ctx iris.Context) {
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	res, err := uc.UserColl.DeleteOne(ctxTimeout, bson.M{"_id": objectID})
-	if err != nil {
+	var existing models.User
+	err = uc.UserColl.FindOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "User not found", nil)
+		return
+	} else if err != nil {
 		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
 		return
 	}
+	if existing.Role == models.RoleOwner {
+		if ctx.Values().GetString("role") != models.RoleOwner {
+			utils.SendResponse(ctx, http.StatusForbidden, "error", "Only an owner can delete an owner", nil)
+			return
+		}
+		last, err := uc.isLastOwner(ctxTimeout, existing)
+		if err != nil {
+			utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+			return
+		}
+		if last {
+			utils.SendResponse(ctx, http.StatusConflict, "error", "A workspace needs at least one owner", nil)
+			return
+		}
+	}
 
-	if res.DeletedCount == 0 {
+	// The role is matched too so a concurrent promotion isn't deleted
+	// without the checks above.
+	var deleted models.User
+	err = uc.UserColl.FindOneAndDelete(ctxTimeout, scoped(ctx, bson.M{"_id": objectID, "role": existing.Role})).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
 		utils.SendResponse(ctx, http.StatusNotFound, "error", "User not found", nil)
 		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
 	}
 
+	uc.Audit.Record(ctx, models.AuditDelete, uc.UserColl.Name(), objectID, deleted, nil)
+
 	utils.SendResponse(ctx, http.StatusOK, "success", "User deleted successfully!", iris.Map{"user_id": objectID})
 }
+
+// UnlockUserByID → POST /users/{id}/unlock
+// Clears the failed-login lockout on an account.
+func (uc *UserController) UnlockUserByID(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid ID", nil)
+		return
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var user models.User
+	err = uc.UserColl.FindOne(ctxTimeout, scoped(ctx, bson.M{"_id": objectID})).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "User not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	if err := uc.Throttler.Reset(ctxTimeout, accountThrottleKey(user.Email)); err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "User unlocked successfully!", iris.Map{"user_id": objectID})
+}
+
+// emailTaken reports whether a user other than except, in any workspace,
+// already has email.
+func (uc *UserController) emailTaken(cctx context.Context, email string, except primitive.ObjectID) (bool, error) {
+	count, err := uc.UserColl.CountDocuments(cctx, bson.M{"email": email, "_id": bson.M{"$ne": except}})
+	return count > 0, err
+}
+
+// isLastOwner reports whether user is the only owner of their workspace.
+func (uc *UserController) isLastOwner(cctx context.Context, user models.User) (bool, error) {
+	owners, err := uc.UserColl.CountDocuments(cctx, bson.M{
+		"workspace_id": user.WorkspaceID,
+		"role":         models.RoleOwner,
+		"_id":          bson.M{"$ne": user.ID},
+	})
+	return owners == 0, err
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userRefs expands created_by/updated_by IDs to names with a single query.
+// IDs of users that no longer exist are left out, so their refs stay nil.
+func userRefs(ctx iris.Context, cctx context.Context, userColl *mongo.Collection, ids ...primitive.ObjectID) (map[primitive.ObjectID]*models.UserRef, error) {
+	refs := map[primitive.ObjectID]*models.UserRef{}
+
+	wanted := make([]primitive.ObjectID, 0, len(ids))
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		if !id.IsZero() && !seen[id] {
+			seen[id] = true
+			wanted = append(wanted, id)
+		}
+	}
+	if len(wanted) == 0 {
+		return refs, nil
+	}
+
+	cursor, err := userColl.Find(cctx,
+		scoped(ctx, bson.M{"_id": bson.M{"$in": wanted}}),
+		options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(cctx)
+
+	for cursor.Next(cctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err == nil {
+			refs[user.ID] = &models.UserRef{
+				ID:   user.ID,
+				Name: strings.TrimSpace(user.FirstName + " " + user.LastName),
+			}
+		}
+	}
+	return refs, cursor.Err()
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"github.com/kataras/iris/v12"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WorkspaceController struct {
+	WorkspaceColl *mongo.Collection
+}
+
+func NewWorkspaceController(workspaceColl *mongo.Collection) *WorkspaceController {
+	return &WorkspaceController{
+		WorkspaceColl: workspaceColl,
+	}
+}
+
+// workspaceID returns the workspace AuthMiddleware scoped this request to.
+func workspaceID(ctx iris.Context) primitive.ObjectID {
+	id, _ := ctx.Values().Get("workspaceID").(primitive.ObjectID)
+	return id
+}
+
+// scoped adds the request's workspace to a Mongo filter so a query can never
+// reach another tenant's documents.
+func scoped(ctx iris.Context, filter bson.M) bson.M {
+	filter["workspace_id"] = workspaceID(ctx)
+	return filter
+}
+
+// GetWorkspace → GET /workspace
+func (wc *WorkspaceController) GetWorkspace(ctx iris.Context) {
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var workspace models.Workspace
+	err := wc.WorkspaceColl.FindOne(timeoutCtx, bson.M{"_id": workspaceID(ctx)}).Decode(&workspace)
+	if err == mongo.ErrNoDocuments {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Workspace not found", nil)
+		return
+	} else if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Workspace fetched successfully!", workspace)
+}
+
+// UpdateWorkspace → PUT /workspace
+func (wc *WorkspaceController) UpdateWorkspace(ctx iris.Context) {
+	var req models.WorkspaceRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "Invalid request payload", nil)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		utils.SendResponse(ctx, http.StatusBadRequest, "error", "name is required", nil)
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := wc.WorkspaceColl.UpdateByID(timeoutCtx, workspaceID(ctx), bson.M{
+		"$set": bson.M{"name": name, "updated_at": time.Now()},
+	})
+	if err != nil {
+		utils.SendResponse(ctx, http.StatusInternalServerError, "error", "Database error: "+err.Error(), nil)
+		return
+	}
+
+	if res.MatchedCount == 0 {
+		utils.SendResponse(ctx, http.StatusNotFound, "error", "Workspace not found", nil)
+		return
+	}
+
+	utils.SendResponse(ctx, http.StatusOK, "success", "Workspace updated successfully!", iris.Map{"id": workspaceID(ctx), "name": name})
+}
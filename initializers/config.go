@@ -0,0 +1,22 @@
+package initializers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+)
+
+// LoadConfig loads the settings the API reads from the environment, so a
+// bad JWT key or timezone stops the server at boot rather than on the first
+// request that needs it.
+func LoadConfig() {
+	if err := utils.LoadJWTKeys(); err != nil {
+		log.Fatal("❌ Invalid JWT keys:", err)
+	}
+	if err := utils.LoadBusinessLocation(); err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	fmt.Println("✅ Configuration loaded!")
+}
@@ -0,0 +1,162 @@
+package initializers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureIndexes creates the indexes the API relies on. CreateMany is idempotent,
+// so this is safe to run on every start.
+func EnsureIndexes(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db := Client.Database(dbName)
+
+	indexes := map[string][]mongo.IndexModel{
+		"users": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}}},
+			{Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}}},
+		},
+		"sales-logs": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "date", Value: 1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "store_id", Value: 1}, {Key: "date", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "lines.product_id", Value: 1}}},
+		},
+		"stores": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}}, Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"is_default": true})},
+		},
+		"stock-transfers": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "date", Value: -1}}},
+			{Keys: bson.D{{Key: "from_log_id", Value: 1}}},
+			{Keys: bson.D{{Key: "to_log_id", Value: 1}}},
+		},
+		"stock-adjustments": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "date", Value: -1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "status", Value: 1}}},
+			{Keys: bson.D{{Key: "sales_log_id", Value: 1}}},
+		},
+		"alert-rules": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}}},
+		},
+		"alerts": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "acknowledged", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "rule_id", Value: 1}, {Key: "sales_log_id", Value: 1}}},
+		},
+		"products": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "sku", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		"holidays": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}}},
+		},
+		"airbnb": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		},
+		"audit-log": {
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			{Keys: bson.D{{Key: "workspace_id", Value: 1}, {Key: "collection", Value: 1}, {Key: "document_id", Value: 1}}},
+		},
+		"refresh-tokens": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "family_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"api-keys": {
+			{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		"login-throttles": {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"password-resets": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"email-changes": {
+			{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"oidc-states": {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"sessions": {
+			{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+		"revoked-tokens": {
+			{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	}
+
+	// Emails were only checked at signup, so older data may share an
+	// address between users. Those are reported, and the unique index waits
+	// until an admin resolves them.
+	if reportDuplicateEmails(ctx, db.Collection("users")) == 0 {
+		indexes["users"] = append(indexes["users"], mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"email": bson.M{"$gt": ""}}),
+		})
+	}
+
+	// The per-store day index started out non-unique; Mongo won't change an
+	// index's options in place, so the old one is dropped first.
+	dropNonUnique(ctx, db.Collection("sales-logs"), "workspace_id_1_store_id_1_date_1")
+
+	for coll, models := range indexes {
+		if _, err := db.Collection(coll).Indexes().CreateMany(ctx, models); err != nil {
+			log.Fatal("❌ Failed to create indexes on "+coll+":", err)
+		}
+	}
+
+	fmt.Println("✅ MongoDB indexes ensured!")
+}
+
+// dropNonUnique drops the named index if it exists without a unique
+// constraint, so EnsureIndexes can recreate it as unique.
+func dropNonUnique(ctx context.Context, coll *mongo.Collection, name string) {
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		log.Fatal("❌ Failed to list indexes on "+coll.Name()+":", err)
+	}
+	for _, spec := range specs {
+		if spec.Name == name && (spec.Unique == nil || !*spec.Unique) {
+			if _, err := coll.Indexes().DropOne(ctx, name); err != nil {
+				log.Fatal("❌ Failed to drop index "+name+":", err)
+			}
+		}
+	}
+}
+
+// reportDuplicateEmails logs every email shared by more than one user and
+// returns how many there are.
+func reportDuplicateEmails(ctx context.Context, users *mongo.Collection) int {
+	cursor, err := users.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"email": bson.M{"$gt": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$email", "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		log.Fatal("❌ Failed to check for duplicate emails:", err)
+	}
+
+	var dups []struct {
+		Email string        `bson:"_id"`
+		IDs   []interface{} `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &dups); err != nil {
+		log.Fatal("❌ Failed to check for duplicate emails:", err)
+	}
+	for _, d := range dups {
+		log.Printf("⚠️  %s is used by %d users %v; rename all but one to enforce unique emails", d.Email, len(d.IDs), d.IDs)
+	}
+	return len(dups)
+}
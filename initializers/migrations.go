@@ -0,0 +1,179 @@
+package initializers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrateDefaultWorkspace adopts data written before workspaces existed.
+// Users, sales logs and statements without a workspace_id are moved into a
+// single "Default workspace" owned by the earliest such user. Every signed-in
+// user could reach every route before roles existed, so the other users
+// become admins to keep that access; an owner can narrow them afterwards. It
+// is a no-op once every user has a workspace.
+func MigrateDefaultWorkspace(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := Client.Database(dbName)
+	orphan := bson.M{"workspace_id": bson.M{"$exists": false}}
+
+	var owner models.User
+	err := db.Collection("users").FindOne(ctx, orphan, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})).Decode(&owner)
+	if err == mongo.ErrNoDocuments {
+		return
+	} else if err != nil {
+		log.Fatal("❌ Workspace migration failed:", err)
+	}
+
+	now := time.Now().UTC()
+	res, err := db.Collection("workspaces").InsertOne(ctx, models.Workspace{
+		Name:      "Default workspace",
+		OwnerID:   owner.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		log.Fatal("❌ Workspace migration failed:", err)
+	}
+	wid := res.InsertedID
+
+	steps := []struct {
+		coll   string
+		filter bson.M
+		set    bson.M
+	}{
+		{"users", bson.M{"_id": owner.ID}, bson.M{"workspace_id": wid, "role": models.RoleOwner}},
+		{"users", bson.M{"workspace_id": bson.M{"$exists": false}, "role": bson.M{"$in": bson.A{nil, ""}}}, bson.M{"workspace_id": wid, "role": models.RoleAdmin}},
+		{"users", orphan, bson.M{"workspace_id": wid}},
+		{"sales-logs", orphan, bson.M{"workspace_id": wid}},
+		{"airbnb", orphan, bson.M{"workspace_id": wid}},
+	}
+	for _, step := range steps {
+		if _, err := db.Collection(step.coll).UpdateMany(ctx, step.filter, bson.M{"$set": step.set}); err != nil {
+			log.Fatal("❌ Workspace migration failed on "+step.coll+":", err)
+		}
+	}
+
+	fmt.Println("✅ Migrated existing data into the default workspace!")
+}
+
+// MigrateEmailVerified marks accounts created before email verification
+// existed as verified, so turning on REQUIRE_VERIFIED_EMAIL doesn't lock them
+// out. Newer accounts always store the field, so they are never touched.
+func MigrateEmailVerified(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	res, err := Client.Database(dbName).Collection("users").UpdateMany(ctx,
+		bson.M{"email_verified": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"email_verified": true}},
+	)
+	if err != nil {
+		log.Fatal("❌ Email verification migration failed:", err)
+	}
+	if res.ModifiedCount > 0 {
+		fmt.Printf("✅ Marked %d existing users as email-verified!\n", res.ModifiedCount)
+	}
+}
+
+// MigrateSalesLogDays files sales logs saved as raw timestamps under the
+// business day they fall on, matching what AddSalesLog now stores. Logs
+// already at midnight UTC are stored days and are never moved again, so the
+// migration is safe to run on every startup whatever BUSINESS_TIMEZONE is. A
+// log whose day is already taken by another log of the same store is left
+// alone and reported.
+func MigrateSalesLogDays(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	coll := Client.Database(dbName).Collection("sales-logs")
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().
+		SetProjection(bson.M{"workspace_id": 1, "store_id": 1, "date": 1}).
+		SetSort(bson.D{{Key: "date", Value: 1}}),
+	)
+	if err != nil {
+		log.Fatal("❌ Sales log day migration failed:", err)
+	}
+	var logs []models.SalesLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		log.Fatal("❌ Sales log day migration failed:", err)
+	}
+
+	// Logs written before stores existed have no store_id yet and share the
+	// zero ID, so they are only compared with each other.
+	dayKey := func(l models.SalesLog, day time.Time) string {
+		return l.WorkspaceID.Hex() + "/" + l.StoreID.Hex() + "/" + day.UTC().Format(time.RFC3339)
+	}
+	taken := map[string]bool{}
+	for _, l := range logs {
+		taken[dayKey(l, l.Date)] = true
+	}
+
+	migrated := 0
+	for _, l := range logs {
+		if utils.CalendarDay(l.Date).Equal(l.Date) {
+			continue
+		}
+		day := utils.BusinessDay(l.Date)
+		key := dayKey(l, day)
+		if taken[key] {
+			log.Printf("⚠️ Sales log %s (%s) shares business day %s with another log; not migrated", l.ID.Hex(), l.Date.Format(time.RFC3339), utils.DayKey(day))
+			continue
+		}
+		if _, err := coll.UpdateByID(ctx, l.ID, bson.M{"$set": bson.M{"date": day}}); err != nil {
+			log.Fatal("❌ Sales log day migration failed:", err)
+		}
+		taken[key] = true
+		migrated++
+	}
+
+	if migrated > 0 {
+		fmt.Printf("✅ Filed %d sales logs under their business day!\n", migrated)
+	}
+}
+
+// MigrateDefaultStores moves sales logs written before stores existed into
+// each workspace's default store, creating a "Main store" where a workspace
+// has none.
+func MigrateDefaultStores(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := Client.Database(dbName)
+	orphan := bson.M{"store_id": bson.M{"$exists": false}}
+
+	workspaces, err := db.Collection("sales-logs").Distinct(ctx, "workspace_id", orphan)
+	if err != nil {
+		log.Fatal("❌ Store migration failed:", err)
+	}
+
+	for _, wid := range workspaces {
+		now := time.Now().UTC()
+		var store models.Store
+		err := db.Collection("stores").FindOneAndUpdate(ctx,
+			bson.M{"workspace_id": wid, "is_default": true},
+			bson.M{"$setOnInsert": bson.M{"name": "Main store", "created_at": now, "updated_at": now}},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&store)
+		if err != nil {
+			log.Fatal("❌ Store migration failed:", err)
+		}
+
+		filter := bson.M{"workspace_id": wid, "store_id": bson.M{"$exists": false}}
+		res, err := db.Collection("sales-logs").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"store_id": store.ID}})
+		if err != nil {
+			log.Fatal("❌ Store migration failed:", err)
+		}
+		fmt.Printf("✅ Moved %d sales logs into %s!\n", res.ModifiedCount, store.Name)
+	}
+}
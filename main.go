@@ -3,7 +3,9 @@ package main
 import (
 	"github.com/Ganesh-S-Pai/pai-finance/controllers"
 	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+	"github.com/Ganesh-S-Pai/pai-finance/models"
 	"github.com/Ganesh-S-Pai/pai-finance/routes"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
 
 	"github.com/iris-contrib/middleware/cors"
 	"github.com/joho/godotenv"
@@ -15,9 +17,18 @@ var app *iris.Application
 func init() {
 	_ = godotenv.Load()
 
+	initializers.LoadConfig()
+
 	initializers.ConnectDB()
+	initializers.EnsureIndexes("mongo-golang")
+	initializers.MigrateDefaultWorkspace("mongo-golang")
+	initializers.MigrateEmailVerified("mongo-golang")
+	initializers.MigrateSalesLogDays("mongo-golang")
+	initializers.MigrateDefaultStores("mongo-golang")
 
 	app = iris.New()
+	// Fly's proxy puts the real client address here; used for login throttling.
+	app.Configure(iris.WithRemoteAddrHeader("Fly-Client-IP"))
 
 	crs := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "https://pai-finance.web.app", "https://d2jqgx7872rq2f.cloudfront.net"},
@@ -33,18 +44,39 @@ func main() {
 
 	db := initializers.Client.Database("mongo-golang")
 	userColl := db.Collection("users")
-	salesColl := db.Collection("sales-logs")
-	airbnbColl := db.Collection("airbnb")
+	revokedColl := db.Collection("revoked-tokens")
+	apiKeyColl := db.Collection("api-keys")
+
+	authMiddleware := controllers.AuthMiddleware(revokedColl, apiKeyColl, userColl)
+
+	// Sales logs, transfers, adjustments and alerts share one controller and
+	// alerter so every route is wired the same way.
+	audit := controllers.NewAuditor(db.Collection("audit-log"))
+	alerts := controllers.NewAlerter(db.Collection("alert-rules"), db.Collection("alerts"), db.Collection("stores"), utils.NewMailerFromEnv(), audit)
+	salesC := controllers.NewSalesController(db.Collection("sales-logs"), userColl, db.Collection("holidays"), db.Collection("products"), db.Collection("stores"), db.Collection("stock-transfers"), db.Collection("stock-adjustments"), audit, alerts)
+
+	routes.WellKnownRoutes(app)
 
 	router := app.Party("/api/v1")
 
 	routes.AuthRoutes(router)
 
-	adminRoutes := router.Party("/admin", controllers.AuthMiddleware(userColl))
+	workspaceRoutes := router.Party("/", authMiddleware)
+	routes.WorkspaceRoutes(workspaceRoutes)
+
+	adminRoutes := router.Party("/admin", authMiddleware, controllers.RequirePermission(models.PermUsers))
 	routes.UserRoutes(adminRoutes)
-	vhiwRoutes := router.Party("/vhiw", controllers.AuthMiddleware(salesColl))
-	routes.SalesLogRoutes(vhiwRoutes)
-	AirbnbRoutes := router.Party("/airbnb", controllers.AuthMiddleware(airbnbColl))
+	routes.AuditRoutes(adminRoutes)
+	vhiwRoutes := router.Party("/vhiw", authMiddleware, controllers.RequirePermission(models.PermSales), controllers.RequireVerifiedEmail())
+	routes.SalesLogRoutes(vhiwRoutes, salesC)
+	routes.HolidayRoutes(vhiwRoutes)
+	routes.ProductRoutes(vhiwRoutes)
+	routes.StoreRoutes(vhiwRoutes)
+	routes.TransferRoutes(vhiwRoutes, salesC)
+	routes.AdjustmentRoutes(vhiwRoutes, salesC)
+	routes.AlertRoutes(vhiwRoutes, alerts)
+	routes.ForecastRoutes(vhiwRoutes)
+	AirbnbRoutes := router.Party("/airbnb", authMiddleware, controllers.RequirePermission(models.PermRental), controllers.RequireVerifiedEmail())
 	routes.StatementRoutes(AirbnbRoutes)
 
 	app.Listen(":8080")
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons stock changes other than through trade.
+const (
+	ReasonDamage  = "damage"
+	ReasonTheft   = "theft"
+	ReasonSample  = "sample"
+	ReasonExpiry  = "expiry"
+	ReasonRecount = "recount"
+	ReasonOther   = "other"
+)
+
+var AdjustmentReasons = []string{ReasonDamage, ReasonTheft, ReasonSample, ReasonExpiry, ReasonRecount, ReasonOther}
+
+func IsValidAdjustmentReason(reason string) bool {
+	for _, r := range AdjustmentReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Adjustments wait for approval unless an admin posts them.
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+)
+
+// StockAdjustment changes a store's physical stock on a day for a reason
+// other than trade. Quantity is signed: negative for damage, theft and the
+// like, either way for recount corrections. Only approved adjustments are
+// posted to the store's sales log, as its Adjustment.
+type StockAdjustment struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID  `bson:"workspace_id" json:"workspace_id"`
+	StoreID     primitive.ObjectID  `bson:"store_id" json:"store_id"`
+	SalesLogID  *primitive.ObjectID `bson:"sales_log_id,omitempty" json:"sales_log_id,omitempty"`
+	Date        time.Time           `bson:"date" json:"date"`
+	Quantity    int                 `bson:"quantity" json:"quantity"`
+	Lines       []AdjustmentLine    `bson:"lines,omitempty" json:"lines,omitempty"`
+	Reason      string              `bson:"reason" json:"reason"`
+	Note        string              `bson:"note,omitempty" json:"note,omitempty"`
+	Status      string              `bson:"status" json:"status"`
+	ReviewNote  string              `bson:"review_note,omitempty" json:"review_note,omitempty"`
+	ReviewedBy  *primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type AdjustmentLine struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	SKU       string             `bson:"sku" json:"sku"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+}
+
+// StockAdjustmentRequest adjusts quantity, or per-SKU lines when the store
+// tracks products. date is filed under its business day like a sales log.
+type StockAdjustmentRequest struct {
+	StoreID  string                  `json:"store_id"`
+	Date     time.Time               `json:"date"`
+	Quantity int                     `json:"quantity"`
+	Lines    []AdjustmentLineRequest `json:"lines"`
+	Reason   string                  `json:"reason"`
+	Note     string                  `json:"note"`
+}
+
+type AdjustmentLineRequest struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// ReviewAdjustmentRequest approves or rejects a pending adjustment.
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note"`
+}
+
+// AdjustmentSummary totals approved adjustments for one store and reason.
+type AdjustmentSummary struct {
+	StoreID   primitive.ObjectID `bson:"store_id" json:"store_id"`
+	StoreName string             `bson:"-" json:"store_name,omitempty"`
+	Reason    string             `bson:"reason" json:"reason"`
+	Count     int                `bson:"count" json:"count"`
+	Added     int                `bson:"added" json:"added"`
+	Removed   int                `bson:"removed" json:"removed"`
+	Net       int                `bson:"net" json:"net"`
+}
@@ -14,16 +14,23 @@ type DateInfo struct {
 
 type AirbnbStatement struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
 	Transaction string             `bson:"transaction" json:"transaction"`
 	Type        string             `bson:"type" json:"type"`
 	Date        DateInfo           `bson:"date_info" json:"date_info"`
 	Nights      int                `bson:"nights,omitempty" json:"nights"`
 	Amount      float64            `bson:"amount" json:"amount"`
 	Remark      string             `bson:"remark,omitempty" json:"remark"`
-	CreatedUser string             `bson:"created_user" json:"created_user"`
-	UpdatedUser string             `bson:"updated_user" json:"updated_user"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	// CreatedUser and UpdatedUser are names typed by the client on records
+	// saved before attribution was server-side; they are no longer written.
+	CreatedUser   string             `bson:"created_user,omitempty" json:"created_user,omitempty"`
+	UpdatedUser   string             `bson:"updated_user,omitempty" json:"updated_user,omitempty"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedByUser *UserRef           `bson:"-" json:"created_by_user,omitempty"`
+	UpdatedByUser *UserRef           `bson:"-" json:"updated_by_user,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // type AirbnbStatementRequest struct {
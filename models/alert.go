@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alert rule kinds.
+const (
+	// AlertAbsoluteDifference fires when |Difference| exceeds Threshold units.
+	AlertAbsoluteDifference = "absolute_difference"
+	// AlertRelativeDifference fires when |Difference| exceeds Threshold
+	// percent of Opening.
+	AlertRelativeDifference = "relative_difference"
+	// AlertNegativePhysical fires when Physical stock drops below zero.
+	AlertNegativePhysical = "negative_physical"
+)
+
+// Alert channel types.
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelInApp   = "in_app"
+)
+
+// AlertRule is checked against every sales log AddSalesLog or
+// UpdateSalesLogByID writes. StoreID narrows it to one store.
+type AlertRule struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID  `bson:"workspace_id" json:"workspace_id"`
+	StoreID     *primitive.ObjectID `bson:"store_id,omitempty" json:"store_id,omitempty"`
+	Name        string              `bson:"name" json:"name"`
+	Kind        string              `bson:"kind" json:"kind"`
+	Threshold   float64             `bson:"threshold" json:"threshold"`
+	Channels    []AlertChannel      `bson:"channels" json:"channels"`
+	Enabled     bool                `bson:"enabled" json:"enabled"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy   primitive.ObjectID  `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// AlertChannel is where a rule's alerts are delivered. Webhooks are signed
+// with Secret, which is only returned when it is generated.
+type AlertChannel struct {
+	Type   string   `bson:"type" json:"type"`
+	URL    string   `bson:"url,omitempty" json:"url,omitempty"`
+	Secret string   `bson:"secret,omitempty" json:"secret,omitempty"`
+	Emails []string `bson:"emails,omitempty" json:"emails,omitempty"`
+}
+
+type AlertRuleRequest struct {
+	StoreID   string         `json:"store_id"`
+	Name      string         `json:"name"`
+	Kind      string         `json:"kind"`
+	Threshold float64        `json:"threshold"`
+	Channels  []AlertChannel `json:"channels"`
+	Enabled   *bool          `json:"enabled"`
+}
+
+// Alert is one rule firing on one sales log. It stays open until someone
+// acknowledges it; open alerts are the in-app feed.
+type Alert struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	WorkspaceID    primitive.ObjectID  `bson:"workspace_id" json:"workspace_id"`
+	RuleID         primitive.ObjectID  `bson:"rule_id" json:"rule_id"`
+	RuleName       string              `bson:"rule_name" json:"rule_name"`
+	Kind           string              `bson:"kind" json:"kind"`
+	Threshold      float64             `bson:"threshold" json:"threshold"`
+	StoreID        primitive.ObjectID  `bson:"store_id" json:"store_id"`
+	SalesLogID     primitive.ObjectID  `bson:"sales_log_id" json:"sales_log_id"`
+	Date           time.Time           `bson:"date" json:"date"`
+	Opening        int                 `bson:"opening" json:"opening"`
+	Physical       int                 `bson:"physical" json:"physical"`
+	Difference     int                 `bson:"difference" json:"difference"`
+	Message        string              `bson:"message" json:"message"`
+	Deliveries     []AlertDelivery     `bson:"deliveries" json:"deliveries"`
+	Acknowledged   bool                `bson:"acknowledged" json:"acknowledged"`
+	AcknowledgedBy *primitive.ObjectID `bson:"acknowledged_by,omitempty" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time          `bson:"acknowledged_at,omitempty" json:"acknowledged_at,omitempty"`
+	Note           string              `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// AlertDelivery records one attempt to deliver an alert through a channel.
+type AlertDelivery struct {
+	Channel string    `bson:"channel" json:"channel"`
+	Target  string    `bson:"target,omitempty" json:"target,omitempty"`
+	Status  string    `bson:"status" json:"status"`
+	Error   string    `bson:"error,omitempty" json:"error,omitempty"`
+	At      time.Time `bson:"at" json:"at"`
+}
+
+type AcknowledgeAlertRequest struct {
+	Note string `json:"note"`
+}
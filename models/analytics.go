@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// SalesAnalytics summarises the sales logs in a date range. Every figure
+// adds up all stores unless the query is narrowed to one. Adjustment is the
+// net of approved stock adjustments, kept apart from trade movements.
+type SalesAnalytics struct {
+	Daily    []AnalyticsDay     `bson:"daily" json:"daily"`
+	Weekly   []AnalyticsPeriod  `bson:"weekly" json:"weekly"`
+	Monthly  []AnalyticsPeriod  `bson:"monthly" json:"monthly"`
+	Yearly   []AnalyticsPeriod  `bson:"yearly" json:"yearly"`
+	Weekdays []AnalyticsWeekday `bson:"weekdays" json:"weekdays"`
+	Totals   []AnalyticsPeriod  `bson:"totals" json:"-"`
+}
+
+// AnalyticsDay is one logged day. MovingAverage is the average daily sales
+// over the logged days in the trailing window, and CumulativeDifference
+// the running total of Difference since the start of the range.
+type AnalyticsDay struct {
+	Date                 time.Time `bson:"_id" json:"date"`
+	Sales                int       `bson:"sales" json:"sales"`
+	Inward               int       `bson:"inward" json:"inward"`
+	Outward              int       `bson:"outward" json:"outward"`
+	Difference           int       `bson:"difference" json:"difference"`
+	Adjustment           int       `bson:"adjustment" json:"adjustment"`
+	MovingAverage        float64   `bson:"moving_average" json:"moving_average"`
+	CumulativeDifference int       `bson:"cumulative_difference" json:"cumulative_difference"`
+}
+
+// AnalyticsPeriod totals a week ("2025-W41"), month ("2025-10"), year
+// ("2025") or the whole range. Start and End are its first and last logged
+// days, and AverageDailySales is over those logged days.
+type AnalyticsPeriod struct {
+	Period            string    `bson:"_id" json:"period"`
+	Start             time.Time `bson:"start" json:"start"`
+	End               time.Time `bson:"end" json:"end"`
+	Days              int       `bson:"days" json:"days"`
+	Sales             int       `bson:"sales" json:"sales"`
+	Inward            int       `bson:"inward" json:"inward"`
+	Outward           int       `bson:"outward" json:"outward"`
+	Difference        int       `bson:"difference" json:"difference"`
+	Adjustment        int       `bson:"adjustment" json:"adjustment"`
+	AverageDailySales float64   `bson:"average_daily_sales" json:"average_daily_sales"`
+}
+
+// AnalyticsWeekday is the day-of-week seasonality of sales. Index is the
+// weekday's average against the range's average daily sales, so 1.2 means
+// that weekday sells 20% above a typical day.
+type AnalyticsWeekday struct {
+	Weekday      int     `bson:"_id" json:"weekday"`
+	Name         string  `bson:"-" json:"name"`
+	Days         int     `bson:"days" json:"days"`
+	Sales        int     `bson:"sales" json:"sales"`
+	AverageSales float64 `bson:"average_sales" json:"average_sales"`
+	Index        float64 `bson:"-" json:"index"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// API key scopes. A key with no scopes acts with its owner's full role.
+const (
+	APIKeyScopeRead   = "read"   // GET requests only
+	APIKeyScopeSales  = "vhiw"   // only the /vhiw party
+	APIKeyScopeRental = "airbnb" // only the /airbnb party
+)
+
+var apiKeyModuleScopes = map[string]string{
+	APIKeyScopeSales:  PermSales,
+	APIKeyScopeRental: PermRental,
+}
+
+// APIKey is a long-lived personal credential for scripts. Only the SHA-256 of
+// the key is stored; Prefix is kept so users can tell their keys apart.
+type APIKey struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	Name        string             `bson:"name" json:"name"`
+	Prefix      string             `bson:"prefix" json:"prefix"`
+	KeyHash     string             `bson:"key_hash" json:"-"`
+	Scopes      []string           `bson:"scopes" json:"scopes"`
+	LastUsedAt  *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func IsValidAPIKeyScope(scope string) bool {
+	if scope == APIKeyScopeRead {
+		return true
+	}
+	_, ok := apiKeyModuleScopes[scope]
+	return ok
+}
+
+// APIKeyReadOnly reports whether scopes restrict a key to GET requests.
+func APIKeyReadOnly(scopes []string) bool {
+	for _, s := range scopes {
+		if s == APIKeyScopeRead {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyAllows reports whether a key with scopes may use a party guarded by
+// perm. Keys naming one or more modules are limited to those modules.
+func APIKeyAllows(scopes []string, perm string) bool {
+	restricted := false
+	for _, s := range scopes {
+		if p, ok := apiKeyModuleScopes[s]; ok {
+			restricted = true
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return !restricted
+}
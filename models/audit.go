@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records one mutation. Changes holds only the fields that
+// differ between the document before and after, keyed by field name.
+type AuditEntry struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID     `bson:"workspace_id" json:"workspace_id"`
+	ActorID     primitive.ObjectID     `bson:"actor_id" json:"actor_id"`
+	APIKeyID    *primitive.ObjectID    `bson:"api_key_id,omitempty" json:"api_key_id,omitempty"`
+	Action      string                 `bson:"action" json:"action"`
+	Collection  string                 `bson:"collection" json:"collection"`
+	DocumentID  primitive.ObjectID     `bson:"document_id" json:"document_id"`
+	Changes     map[string]AuditChange `bson:"changes" json:"changes"`
+	IP          string                 `bson:"ip" json:"ip"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+}
+
+type AuditChange struct {
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockForecast projects one store's stock, or one product's within it,
+// forward from today. Stock is what the latest log, dated AsOf, counted;
+// EstimatedStock is what is left today after selling at Velocity, the
+// estimated daily sales, on the trading days since. Stale is set once that
+// log is older than the lead time. DaysOfCover and StockOutDate are empty
+// when nothing is selling.
+type StockForecast struct {
+	StoreID        primitive.ObjectID  `json:"store_id"`
+	StoreName      string              `json:"store_name"`
+	ProductID      *primitive.ObjectID `json:"product_id,omitempty"`
+	SKU            string              `json:"sku,omitempty"`
+	ProductName    string              `json:"product_name,omitempty"`
+	AsOf           time.Time           `json:"as_of"`
+	Stock          int                 `json:"stock"`
+	EstimatedStock int                 `json:"estimated_stock"`
+	Stale          bool                `json:"stale"`
+	DaysObserved   int                 `json:"days_observed"`
+	Velocity       float64             `json:"velocity"`
+	DaysOfCover    *float64            `json:"days_of_cover"`
+	StockOutDate   *time.Time          `json:"stock_out_date"`
+	Reorder        ReorderSuggestion   `json:"reorder"`
+}
+
+// ReorderSuggestion says when to reorder and how much. ReorderPoint covers
+// sales over the lead time plus the safety stock; an order placed then
+// should bring stock up to OrderUpTo. Quantity is non-zero only once stock
+// is at or below the reorder point.
+type ReorderSuggestion struct {
+	ReorderPoint int        `json:"reorder_point"`
+	OrderUpTo    int        `json:"order_up_to"`
+	ReorderNow   bool       `json:"reorder_now"`
+	ReorderBy    *time.Time `json:"reorder_by"`
+	Quantity     int        `json:"quantity"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Holiday is a day the shop is closed, so no sales log is expected for it.
+// A weekly holiday repeats on Weekday; otherwise it covers Date only.
+type Holiday struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	Name        string             `bson:"name" json:"name"`
+	Date        *time.Time         `bson:"date,omitempty" json:"date,omitempty"`
+	Weekday     *time.Weekday      `bson:"weekday,omitempty" json:"weekday,omitempty"`
+	CreatedBy   primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// HolidayRequest takes either a date (YYYY-MM-DD) or a weekday (0 = Sunday).
+type HolidayRequest struct {
+	Name    string        `json:"name"`
+	Date    string        `json:"date"`
+	Weekday *time.Weekday `json:"weekday"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LoginThrottle tracks consecutive failed logins for one key, either
+// "account:<email>" or "ip:<address>". Documents expire on their own once
+// ExpiresAt passes without further failures.
+type LoginThrottle struct {
+	Key           string    `bson:"_id" json:"key"`
+	Failures      int       `bson:"failures" json:"failures"`
+	LockedUntil   time.Time `bson:"locked_until" json:"locked_until"`
+	LastFailureAt time.Time `bson:"last_failure_at" json:"last_failure_at"`
+	ExpiresAt     time.Time `bson:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCState is an in-flight OpenID Connect sign-in. It is keyed by the
+// SHA-256 of the state parameter and consumed by the callback.
+type OIDCState struct {
+	ID           string    `bson:"_id"`
+	Provider     string    `bson:"provider"`
+	Nonce        string    `bson:"nonce"`
+	CodeVerifier string    `bson:"code_verifier"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+	CreatedAt    time.Time `bson:"created_at"`
+}
+
+// Identity links a user to an account at an OpenID Connect provider.
+type Identity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"subject"`
+	Email    string    `bson:"email" json:"email"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+const (
+	RoleOwner      = "owner"
+	RoleAdmin      = "admin"
+	RoleStaff      = "staff"
+	RoleAccountant = "accountant"
+	RoleMember     = "member"
+)
+
+// Permissions gate whole API parties; see RequirePermission.
+const (
+	PermUsers  = "users"
+	PermSales  = "sales"
+	PermRental = "rental"
+)
+
+var rolePermissions = map[string][]string{
+	RoleOwner:      {PermUsers, PermSales, PermRental},
+	RoleAdmin:      {PermUsers, PermSales, PermRental},
+	RoleStaff:      {PermSales},
+	RoleAccountant: {PermSales, PermRental},
+	RoleMember:     {},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role, perm string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
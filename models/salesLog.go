@@ -7,31 +7,93 @@ import (
 )
 
 type SalesLog struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Date       time.Time          `bson:"date" json:"date"`
-	Opening    int                `bson:"opening" json:"opening"`
-	Inward     int                `bson:"inward" json:"inward"`
-	Sales      int                `bson:"sales" json:"sales"`
-	Outward    int                `bson:"outward" json:"outward"`
-	Physical   int                `bson:"physical" json:"physical"`
-	System     int                `bson:"system" json:"system"`
-	Difference int                `bson:"difference" json:"difference"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID   primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	StoreID       primitive.ObjectID `bson:"store_id" json:"store_id"`
+	Date          time.Time          `bson:"date" json:"date"`
+	Opening       int                `bson:"opening" json:"opening"`
+	Inward        int                `bson:"inward" json:"inward"`
+	Sales         int                `bson:"sales" json:"sales"`
+	Outward       int                `bson:"outward" json:"outward"`
+	TransferIn    int                `bson:"transfer_in" json:"transfer_in"`
+	TransferOut   int                `bson:"transfer_out" json:"transfer_out"`
+	Adjustment    int                `bson:"adjustment" json:"adjustment"`
+	Physical      int                `bson:"physical" json:"physical"`
+	System        int                `bson:"system" json:"system"`
+	Difference    int                `bson:"difference" json:"difference"`
+	Lines         []SalesLogLine     `bson:"lines,omitempty" json:"lines,omitempty"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedByUser *UserRef           `bson:"-" json:"created_by_user,omitempty"`
+	UpdatedByUser *UserRef           `bson:"-" json:"updated_by_user,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// SalesLogLine is one product's movement on a day. When a log has lines,
+// the log's own figures are their rollup.
+type SalesLogLine struct {
+	ProductID   primitive.ObjectID `bson:"product_id" json:"product_id"`
+	SKU         string             `bson:"sku" json:"sku"`
+	Opening     int                `bson:"opening" json:"opening"`
+	Inward      int                `bson:"inward" json:"inward"`
+	Sales       int                `bson:"sales" json:"sales"`
+	Outward     int                `bson:"outward" json:"outward"`
+	TransferIn  int                `bson:"transfer_in" json:"transfer_in"`
+	TransferOut int                `bson:"transfer_out" json:"transfer_out"`
+	Adjustment  int                `bson:"adjustment" json:"adjustment"`
+	Physical    int                `bson:"physical" json:"physical"`
+	System      int                `bson:"system" json:"system"`
+	Difference  int                `bson:"difference" json:"difference"`
+}
+
+// SalesLogRequest files a log under store_id, or the workspace's default
+// store when it is empty.
 type SalesLogRequest struct {
-	Date    time.Time `bson:"date" json:"date"`
-	Inward  int       `bson:"inward" json:"inward"`
-	Sales   int       `bson:"sales" json:"sales"`
-	Outward int       `bson:"outward" json:"outward"`
-	System  int       `bson:"system" json:"system"`
+	StoreID string                `bson:"store_id" json:"store_id"`
+	Date    time.Time             `bson:"date" json:"date"`
+	Inward  int                   `bson:"inward" json:"inward"`
+	Sales   int                   `bson:"sales" json:"sales"`
+	Outward int                   `bson:"outward" json:"outward"`
+	System  int                   `bson:"system" json:"system"`
+	Lines   []SalesLogLineRequest `bson:"lines" json:"lines"`
 }
 
 type SalesLogUpdateRequest struct {
-	Date    time.Time `bson:"date" json:"date"`
-	Inward  int       `bson:"inward" json:"inward"`
-	Sales   int       `bson:"sales" json:"sales"`
-	Outward int       `bson:"outward" json:"outward"`
-	System  int       `bson:"system" json:"system"`
+	Date    time.Time             `bson:"date" json:"date"`
+	Inward  int                   `bson:"inward" json:"inward"`
+	Sales   int                   `bson:"sales" json:"sales"`
+	Outward int                   `bson:"outward" json:"outward"`
+	System  int                   `bson:"system" json:"system"`
+	Lines   []SalesLogLineRequest `bson:"lines" json:"lines"`
+}
+
+// SalesLogLineRequest is one product's figures in a posted log. Opening is
+// only used for a product's first log; after that it carries forward.
+type SalesLogLineRequest struct {
+	SKU     string `json:"sku"`
+	Opening int    `json:"opening"`
+	Inward  int    `json:"inward"`
+	Sales   int    `json:"sales"`
+	Outward int    `json:"outward"`
+	System  int    `json:"system"`
+}
+
+// AffectedDay is a log whose stock figures were recomputed because an
+// earlier day changed.
+type AffectedDay struct {
+	ID         primitive.ObjectID `json:"id"`
+	StoreID    primitive.ObjectID `json:"store_id"`
+	Date       time.Time          `json:"date"`
+	Opening    int                `json:"opening"`
+	Physical   int                `json:"physical"`
+	Difference int                `json:"difference"`
+}
+
+// ImportRowError is a problem with one row of an imported spreadsheet. Row
+// is the spreadsheet row number, counting the header as row 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
 }
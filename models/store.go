@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store is a counter or location with its own stock ledger. Each workspace
+// has one default store, used when a request doesn't name one.
+type Store struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	Name        string             `bson:"name" json:"name"`
+	Address     string             `bson:"address,omitempty" json:"address,omitempty"`
+	IsDefault   bool               `bson:"is_default" json:"is_default"`
+	CreatedBy   primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy   primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type StoreRequest struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// StockTransfer moves stock between two stores on a day. It is posted as
+// transfer_out on the source store's log and transfer_in on the
+// destination's, so the pair always balances.
+type StockTransfer struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	Date        time.Time          `bson:"date" json:"date"`
+	FromStoreID primitive.ObjectID `bson:"from_store_id" json:"from_store_id"`
+	ToStoreID   primitive.ObjectID `bson:"to_store_id" json:"to_store_id"`
+	FromLogID   primitive.ObjectID `bson:"from_log_id" json:"from_log_id"`
+	ToLogID     primitive.ObjectID `bson:"to_log_id" json:"to_log_id"`
+	Quantity    int                `bson:"quantity" json:"quantity"`
+	Lines       []TransferLine     `bson:"lines,omitempty" json:"lines,omitempty"`
+	Note        string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy   primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type TransferLine struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	SKU       string             `bson:"sku" json:"sku"`
+	Quantity  int                `bson:"quantity" json:"quantity"`
+}
+
+// StockTransferRequest moves quantity, or per-SKU lines when the stores
+// track products. date is filed under its business day like a sales log.
+type StockTransferRequest struct {
+	FromStoreID string                `json:"from_store_id"`
+	ToStoreID   string                `json:"to_store_id"`
+	Date        time.Time             `json:"date"`
+	Quantity    int                   `json:"quantity"`
+	Lines       []TransferLineRequest `json:"lines"`
+	Note        string                `json:"note"`
+}
+
+type TransferLineRequest struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// ConsolidatedDay is every store's figures for one day added together.
+// Transfers between stores cancel out, so they are reported separately
+// rather than as inward or outward, as are stock adjustments.
+type ConsolidatedDay struct {
+	Date        time.Time  `bson:"_id" json:"date"`
+	Opening     int        `bson:"opening" json:"opening"`
+	Inward      int        `bson:"inward" json:"inward"`
+	Sales       int        `bson:"sales" json:"sales"`
+	Outward     int        `bson:"outward" json:"outward"`
+	Transferred int        `bson:"transferred" json:"transferred"`
+	Adjustment  int        `bson:"adjustment" json:"adjustment"`
+	Physical    int        `bson:"physical" json:"physical"`
+	System      int        `bson:"system" json:"system"`
+	Difference  int        `bson:"difference" json:"difference"`
+	Stores      []StoreDay `bson:"stores" json:"stores"`
+}
+
+// StoreDay is one store's share of a ConsolidatedDay.
+type StoreDay struct {
+	StoreID     primitive.ObjectID `bson:"store_id" json:"store_id"`
+	StoreName   string             `bson:"-" json:"store_name,omitempty"`
+	LogID       primitive.ObjectID `bson:"log_id" json:"log_id"`
+	Opening     int                `bson:"opening" json:"opening"`
+	Inward      int                `bson:"inward" json:"inward"`
+	Sales       int                `bson:"sales" json:"sales"`
+	Outward     int                `bson:"outward" json:"outward"`
+	TransferIn  int                `bson:"transfer_in" json:"transfer_in"`
+	TransferOut int                `bson:"transfer_out" json:"transfer_out"`
+	Adjustment  int                `bson:"adjustment" json:"adjustment"`
+	Physical    int                `bson:"physical" json:"physical"`
+	System      int                `bson:"system" json:"system"`
+	Difference  int                `bson:"difference" json:"difference"`
+}
@@ -7,35 +7,72 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	FirstName string             `bson:"first_name" json:"first_name"`
-	LastName  string             `bson:"last_name" json:"last_name"`
-	Email     string             `bson:"email" json:"email"`
-	Password  string             `bson:"password" json:"-"`
-	Phone     string             `bson:"phone" json:"phone"`
-	DOB       time.Time          `bson:"dob" json:"dob"`
-	Gender    string             `bson:"gender" json:"gender"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID   primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	FirstName     string             `bson:"first_name" json:"first_name"`
+	LastName      string             `bson:"last_name" json:"last_name"`
+	Email         string             `bson:"email" json:"email"`
+	EmailVerified bool               `bson:"email_verified" json:"email_verified"`
+	Password      string             `bson:"password" json:"-"`
+	Phone         string             `bson:"phone" json:"phone"`
+	DOB           time.Time          `bson:"dob" json:"dob"`
+	Gender        string             `bson:"gender" json:"gender"`
+	Role          string             `bson:"role" json:"role"`
+	TOTP          TOTP               `bson:"totp,omitempty" json:"-"`
+	TOTPEnabled   bool               `bson:"totp_enabled" json:"totp_enabled"`
+	Identities    []Identity         `bson:"identities,omitempty" json:"-"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TOTP holds a user's second-factor state. PendingSecret is set by setup and
+// only becomes Secret once a code from it has been verified.
+type TOTP struct {
+	Secret        string   `bson:"secret,omitempty"`
+	PendingSecret string   `bson:"pending_secret,omitempty"`
+	LastStep      int64    `bson:"last_step,omitempty"`
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"` // SHA-256 hashes
 }
 
 type UserRequest struct {
-	FirstName string             `bson:"first_name" json:"first_name"`
-	LastName  string             `bson:"last_name" json:"last_name"`
-	Email     string             `bson:"email" json:"email"`
-	Phone     string             `bson:"phone" json:"phone"`
-	DOB       time.Time          `bson:"dob" json:"dob"`
-	Gender    string             `bson:"gender" json:"gender"`
+	WorkspaceID   primitive.ObjectID `bson:"workspace_id" json:"-"`
+	FirstName     string             `bson:"first_name" json:"first_name"`
+	LastName      string             `bson:"last_name" json:"last_name"`
+	Email         string             `bson:"email" json:"email"`
+	EmailVerified bool               `bson:"email_verified" json:"-"`
+	Phone         string             `bson:"phone" json:"phone"`
+	DOB           time.Time          `bson:"dob" json:"dob"`
+	Gender        string             `bson:"gender" json:"gender"`
+	Role          string             `bson:"role" json:"role"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"-"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by,omitempty" json:"-"`
 }
 
 type UserResponse struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	FirstName string             `bson:"first_name" json:"first_name"`
-	LastName  string             `bson:"last_name" json:"last_name"`
-	Email     string             `bson:"email" json:"email"`
-	Phone     string             `bson:"phone" json:"phone"`
-	DOB       time.Time          `bson:"dob" json:"dob"`
-	Gender    string             `bson:"gender" json:"gender"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WorkspaceID   primitive.ObjectID `bson:"workspace_id" json:"workspace_id"`
+	FirstName     string             `bson:"first_name" json:"first_name"`
+	LastName      string             `bson:"last_name" json:"last_name"`
+	Email         string             `bson:"email" json:"email"`
+	EmailVerified bool               `bson:"email_verified" json:"email_verified"`
+	Phone         string             `bson:"phone" json:"phone"`
+	DOB           time.Time          `bson:"dob" json:"dob"`
+	Gender        string             `bson:"gender" json:"gender"`
+	Role          string             `bson:"role" json:"role"`
+	TOTPEnabled   bool               `bson:"totp_enabled" json:"totp_enabled"`
+	CreatedBy     primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	CreatedByUser *UserRef           `bson:"-" json:"created_by_user,omitempty"`
+	UpdatedByUser *UserRef           `bson:"-" json:"updated_by_user,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// UserRef is a user expanded to a display name, used to show who created or
+// last changed a record.
+type UserRef struct {
+	ID   primitive.ObjectID `json:"id"`
+	Name string             `json:"name"`
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func AdjustmentRoutes(rg router.Party, salesC *controllers.SalesController) {
+	router := rg.Party("/adjustments")
+
+	router.Use(iris.Compression)
+	router.Get("/", salesC.GetStockAdjustments)
+	router.Get("/summary", salesC.GetStockAdjustmentSummary)
+	router.Post("/", salesC.AddStockAdjustment)
+	router.Post("/{id}/approve", controllers.RequirePermission(models.PermUsers), salesC.ApproveStockAdjustment)
+	router.Post("/{id}/reject", controllers.RequirePermission(models.PermUsers), salesC.RejectStockAdjustment)
+	router.Delete("/{id}", salesC.DeleteStockAdjustmentByID)
+}
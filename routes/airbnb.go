@@ -14,10 +14,11 @@ func StatementRoutes(rg router.Party) {
 	db := initializers.Client.Database("mongo-golang")
 	airbnbColl := db.Collection("airbnb")
 
-	airbnbC := controllers.NewAirbnbController(airbnbColl)
+	airbnbC := controllers.NewAirbnbController(airbnbColl, db.Collection("users"), controllers.NewAuditor(db.Collection("audit-log")))
 
 	router.Use(iris.Compression)
 	router.Get("/", airbnbC.GetAllStatements)
+	router.Get("/export", airbnbC.ExportStatements)
 	router.Post("/", airbnbC.AddStatement)
 	router.Put("/{id}", airbnbC.UpdateStatementByID)
 	router.Delete("/{id}", airbnbC.DeleteStatementByID)
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func AlertRoutes(rg router.Party, alertC *controllers.Alerter) {
+	router := rg.Party("/alerts")
+
+	router.Use(iris.Compression)
+	router.Get("/", alertC.GetAlerts)
+	router.Post("/{id}/acknowledge", alertC.AcknowledgeAlert)
+
+	// Rules exist to catch discrepancies staff cause, so only admins manage them.
+	rules := router.Party("/rules", controllers.RequirePermission(models.PermUsers))
+	rules.Get("/", alertC.GetAlertRules)
+	rules.Post("/", alertC.AddAlertRule)
+	rules.Put("/{id}", alertC.UpdateAlertRuleByID)
+	rules.Delete("/{id}", alertC.DeleteAlertRuleByID)
+}
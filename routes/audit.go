@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func AuditRoutes(rg router.Party) {
+	router := rg.Party("/audit")
+
+	db := initializers.Client.Database("mongo-golang")
+	auditColl := db.Collection("audit-log")
+
+	auditC := controllers.NewAuditor(auditColl)
+
+	router.Use(iris.Compression)
+	router.Get("/", auditC.GetAuditLog)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/Ganesh-S-Pai/pai-finance/controllers"
 	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+	"github.com/Ganesh-S-Pai/pai-finance/utils"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/core/router"
@@ -13,10 +14,56 @@ func AuthRoutes(rg router.Party) {
 
 	db := initializers.Client.Database("mongo-golang")
 	userColl := db.Collection("users")
+	workspaceColl := db.Collection("workspaces")
+	refreshColl := db.Collection("refresh-tokens")
+	revokedColl := db.Collection("revoked-tokens")
+	resetColl := db.Collection("password-resets")
+	throttleColl := db.Collection("login-throttles")
+	apiKeyColl := db.Collection("api-keys")
+	sessionColl := db.Collection("sessions")
+	emailChangeColl := db.Collection("email-changes")
+	oidcStateColl := db.Collection("oidc-states")
 
-	authC := controllers.NewAuthController(userColl)
+	throttler := controllers.NewLoginThrottler(throttleColl)
+	authC := controllers.NewAuthController(userColl, workspaceColl, refreshColl, revokedColl, resetColl, sessionColl, emailChangeColl, utils.NewMailerFromEnv(), throttler)
+	apiKeyC := controllers.NewAPIKeyController(apiKeyColl)
+	oidcC := controllers.NewOIDCController(authC, oidcStateColl, utils.OIDCProvidersFromEnv())
+	authMiddleware := controllers.AuthMiddleware(revokedColl, apiKeyColl, userColl)
 
 	router.Use(iris.Compression)
 	router.Post("/signup", authC.Signup)
 	router.Post("/login", authC.Login)
+	router.Post("/refresh", authC.Refresh)
+	router.Post("/logout", authC.Logout)
+	router.Post("/forgot-password", authC.ForgotPassword)
+	router.Post("/reset-password", authC.ResetPassword)
+	router.Post("/verify-email", authC.VerifyEmail)
+	router.Post("/resend-verification", authMiddleware, controllers.DenyAPIKeys, authC.ResendVerification)
+	router.Post("/change-password", authMiddleware, controllers.DenyAPIKeys, authC.ChangePassword)
+	router.Post("/change-email", authMiddleware, controllers.DenyAPIKeys, authC.ChangeEmail)
+	router.Post("/confirm-email-change", authC.ConfirmEmailChange)
+	router.Get("/me", authMiddleware, authC.GetMe)
+	router.Put("/me", authMiddleware, controllers.DenyAPIKeys, authC.UpdateMe)
+
+	twoFactor := router.Party("/2fa")
+	twoFactor.Post("/verify", authC.VerifyTwoFactor)
+	twoFactor.Post("/setup", authMiddleware, controllers.DenyAPIKeys, authC.SetupTwoFactor)
+	twoFactor.Post("/enable", authMiddleware, controllers.DenyAPIKeys, authC.EnableTwoFactor)
+	twoFactor.Post("/disable", authMiddleware, controllers.DenyAPIKeys, authC.DisableTwoFactor)
+	twoFactor.Post("/recovery-codes", authMiddleware, controllers.DenyAPIKeys, authC.RegenerateRecoveryCodes)
+
+	oidc := router.Party("/oidc")
+	oidc.Get("/", oidcC.GetOIDCProviders)
+	oidc.Get("/{provider}", oidcC.StartOIDCLogin)
+	oidc.Post("/{provider}/callback", oidcC.FinishOIDCLogin)
+
+	sessions := router.Party("/sessions", authMiddleware, controllers.DenyAPIKeys)
+	sessions.Get("/", authC.GetSessions)
+	sessions.Delete("/", authC.RevokeAllSessions)
+	sessions.Delete("/{id}", authC.RevokeSessionByID)
+
+	apiKeys := router.Party("/api-keys", authMiddleware, controllers.DenyAPIKeys)
+	apiKeys.Get("/", apiKeyC.GetAPIKeys)
+	apiKeys.Post("/", apiKeyC.AddAPIKey)
+	apiKeys.Delete("/{id}", apiKeyC.RevokeAPIKeyByID)
 }
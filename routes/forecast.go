@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func ForecastRoutes(rg router.Party) {
+	router := rg.Party("/forecast")
+
+	db := initializers.Client.Database("mongo-golang")
+
+	forecastC := controllers.NewForecastController(db.Collection("sales-logs"), db.Collection("stores"), db.Collection("products"), db.Collection("holidays"))
+
+	router.Use(iris.Compression)
+	router.Get("/", forecastC.GetStockForecast)
+}
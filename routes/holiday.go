@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func HolidayRoutes(rg router.Party) {
+	router := rg.Party("/holidays")
+
+	db := initializers.Client.Database("mongo-golang")
+	holidayColl := db.Collection("holidays")
+
+	holidayC := controllers.NewHolidayController(holidayColl)
+
+	router.Use(iris.Compression)
+	router.Get("/", holidayC.GetHolidays)
+	router.Post("/", holidayC.AddHoliday)
+	router.Delete("/{id}", holidayC.DeleteHolidayByID)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func ProductRoutes(rg router.Party) {
+	router := rg.Party("/products")
+
+	db := initializers.Client.Database("mongo-golang")
+	productColl := db.Collection("products")
+	salesColl := db.Collection("sales-logs")
+
+	productC := controllers.NewProductController(productColl, salesColl, controllers.NewAuditor(db.Collection("audit-log")))
+
+	router.Use(iris.Compression)
+	router.Get("/", productC.GetProducts)
+	router.Get("/{id}", productC.GetProductByID)
+	router.Post("/", productC.AddProduct)
+	router.Put("/{id}", productC.UpdateProductByID)
+	router.Delete("/{id}", productC.DeleteProductByID)
+}
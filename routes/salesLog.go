@@ -2,24 +2,23 @@ package routes
 
 import (
 	"github.com/Ganesh-S-Pai/pai-finance/controllers"
-	"github.com/Ganesh-S-Pai/pai-finance/initializers"
 
 	"github.com/kataras/iris/v12"
 	"github.com/kataras/iris/v12/core/router"
 )
 
-func SalesLogRoutes(rg router.Party) {
+func SalesLogRoutes(rg router.Party, salesC *controllers.SalesController) {
 	router := rg.Party("/sales-logs")
 
-	db := initializers.Client.Database("mongo-golang")
-	salesColl := db.Collection("sales-logs")
-
-	salesC := controllers.NewSalesController(salesColl)
-
 	router.Use(iris.Compression)
 	router.Get("/", salesC.GetSalesLogs)
+	router.Get("/gaps", salesC.GetSalesLogGaps)
+	router.Get("/consolidated", salesC.GetConsolidatedSalesLogs)
+	router.Get("/analytics", salesC.GetSalesLogAnalytics)
+	router.Get("/export", salesC.ExportSalesLogs)
 	router.Get("/{id}", salesC.GetSalesLogByID)
 	router.Post("/", salesC.AddSalesLog)
+	router.Post("/import", salesC.ImportSalesLogs)
 	router.Put("/{id}", salesC.UpdateSalesLogByID)
 	router.Delete("/{id}", salesC.DeleteSalesLogByID)
 }
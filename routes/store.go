@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func StoreRoutes(rg router.Party) {
+	router := rg.Party("/stores")
+
+	db := initializers.Client.Database("mongo-golang")
+	storeColl := db.Collection("stores")
+	salesColl := db.Collection("sales-logs")
+
+	storeC := controllers.NewStoreController(storeColl, salesColl, controllers.NewAuditor(db.Collection("audit-log")))
+
+	router.Use(iris.Compression)
+	router.Get("/", storeC.GetStores)
+	router.Post("/", storeC.AddStore)
+	router.Put("/{id}", storeC.UpdateStoreByID)
+	router.Delete("/{id}", storeC.DeleteStoreByID)
+}
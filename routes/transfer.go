@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func TransferRoutes(rg router.Party, salesC *controllers.SalesController) {
+	router := rg.Party("/transfers")
+
+	router.Use(iris.Compression)
+	router.Get("/", salesC.GetStockTransfers)
+	router.Post("/", salesC.AddStockTransfer)
+	router.Delete("/{id}", salesC.DeleteStockTransferByID)
+}
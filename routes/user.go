@@ -13,8 +13,10 @@ func UserRoutes(rg router.Party) {
 
 	db := initializers.Client.Database("mongo-golang")
 	userColl := db.Collection("users")
+	throttleColl := db.Collection("login-throttles")
+	auditColl := db.Collection("audit-log")
 
-	userC := controllers.NewUserController(userColl)
+	userC := controllers.NewUserController(userColl, controllers.NewLoginThrottler(throttleColl), controllers.NewAuditor(auditColl))
 
 	router.Use(iris.Compression)
 	router.Get("/", userC.GetAllUsers)
@@ -22,4 +24,5 @@ func UserRoutes(rg router.Party) {
 	router.Post("/", userC.AddUser)
 	router.Put("/{id}", userC.UpdateUserByID)
 	router.Delete("/{id}", userC.DeleteUserByID)
+	router.Post("/{id}/unlock", userC.UnlockUserByID)
 }
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func WellKnownRoutes(rg router.Party) {
+	router := rg.Party("/.well-known")
+
+	router.Get("/jwks.json", controllers.GetJWKS)
+}
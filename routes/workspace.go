@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/Ganesh-S-Pai/pai-finance/controllers"
+	"github.com/Ganesh-S-Pai/pai-finance/initializers"
+	"github.com/Ganesh-S-Pai/pai-finance/models"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/core/router"
+)
+
+func WorkspaceRoutes(rg router.Party) {
+	router := rg.Party("/workspace")
+
+	db := initializers.Client.Database("mongo-golang")
+	workspaceColl := db.Collection("workspaces")
+
+	workspaceC := controllers.NewWorkspaceController(workspaceColl)
+
+	router.Use(iris.Compression)
+	router.Get("/", workspaceC.GetWorkspace)
+	router.Put("/", controllers.RequirePermission(models.PermUsers), workspaceC.UpdateWorkspace)
+}
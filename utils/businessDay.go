@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // the runtime image may not ship a zoneinfo database
+)
+
+var businessLoc *time.Location
+
+// LoadBusinessLocation reads BUSINESS_TIMEZONE (an IANA name, default
+// Asia/Kolkata). It runs once at startup, after .env has been read.
+func LoadBusinessLocation() error {
+	name := getenv("BUSINESS_TIMEZONE", "Asia/Kolkata")
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid BUSINESS_TIMEZONE %s: %w", name, err)
+	}
+	businessLoc = loc
+	return nil
+}
+
+// BusinessLocation is the timezone the shop keeps its books in.
+func BusinessLocation() *time.Location {
+	if businessLoc == nil {
+		panic("utils: BusinessLocation used before LoadBusinessLocation")
+	}
+	return businessLoc
+}
+
+// BusinessDay returns the business calendar day instant t falls on. Days are
+// stored as midnight UTC of that calendar date, so "2025-10-08T20:00:00Z" is
+// the 9th in IST and becomes "2025-10-09T00:00:00Z".
+func BusinessDay(t time.Time) time.Time {
+	y, m, d := t.In(BusinessLocation()).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Today is the current business day.
+func Today() time.Time {
+	return BusinessDay(time.Now())
+}
+
+// CalendarDay truncates a stored day to midnight UTC. Unlike BusinessDay it
+// does not shift timezones, so it is safe to apply to values that are
+// already days.
+func CalendarDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// DayKey formats a stored day, e.g. "2025-10-08".
+func DayKey(t time.Time) string {
+	return CalendarDay(t).Format("2006-01-02")
+}
+
+// ParseDay parses a YYYY-MM-DD calendar date as a stored day.
+func ParseDay(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, time.UTC)
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "math"
+
+// Ways of estimating sales velocity.
+const (
+	ForecastSES           = "ses"
+	ForecastMovingAverage = "moving_average"
+)
+
+// SmoothExponential returns the simple exponential smoothing level of
+// series, oldest value first. alpha in (0, 1] weights recent values; the
+// level starts at the first value.
+func SmoothExponential(series []float64, alpha float64) float64 {
+	if len(series) == 0 {
+		return 0
+	}
+	level := series[0]
+	for _, v := range series[1:] {
+		level = alpha*v + (1-alpha)*level
+	}
+	return level
+}
+
+// MovingAverage returns the mean of the last window values of series.
+func MovingAverage(series []float64, window int) float64 {
+	if len(series) == 0 || window <= 0 {
+		return 0
+	}
+	if len(series) > window {
+		series = series[len(series)-window:]
+	}
+	var sum float64
+	for _, v := range series {
+		sum += v
+	}
+	return sum / float64(len(series))
+}
+
+// RoundTo rounds v to places decimal places.
+func RoundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FormatIndian groups digits the Indian way, e.g. 1234567.5 with two
+// decimals is "12,34,567.50".
+func FormatIndian(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	if len(whole) > 3 {
+		head := whole[:len(whole)-3]
+		for len(head) > 2 {
+			cut := len(head) % 2
+			if cut == 0 {
+				cut = 2
+			}
+			b.WriteString(head[:cut])
+			b.WriteByte(',')
+			head = head[cut:]
+		}
+		b.WriteString(head)
+		b.WriteByte(',')
+		whole = whole[len(whole)-3:]
+	}
+	b.WriteString(whole)
+	if frac != "" {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// FormatINR formats an amount in rupees, e.g. "₹12,34,567.50".
+func FormatINR(v float64) string {
+	s := FormatIndian(v, 2)
+	if strings.HasPrefix(s, "-") {
+		return "-₹" + s[1:]
+	}
+	return "₹" + s
+}
+
+// XLSXINRFormat is an Excel number format showing rupees with Indian digit
+// grouping, which Excel can't express with a plain #,##0 pattern.
+const XLSXINRFormat = `[>=10000000]"₹"##\,##\,##\,##0.00;[>=100000]"₹"##\,##\,##0.00;"₹"#,##0.00`
@@ -9,7 +9,17 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var jwtSecret = []byte(getenv("JWT_SECRET", "supersecretkey"))
+// Issuer is the iss claim on every token, so other services can check who
+// minted it before trusting the JWKS.
+func Issuer() string {
+	return getenv("JWT_ISSUER", "pai-finance")
+}
+
+// Audience is the aud claim on access tokens. Services trusting the JWKS
+// should require it; no other token pai-finance issues carries it.
+func Audience() string {
+	return getenv("JWT_AUDIENCE", "pai-finance-api")
+}
 
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
@@ -18,44 +28,205 @@ func getenv(key, def string) string {
 	return def
 }
 
-func CreateToken(userID primitive.ObjectID, ttlHours int) (string, error) {
-	if ttlHours <= 0 {
-		ttlHours = 72
+// TokenClaims holds the claims pai-finance puts in an access token.
+// CreateToken fills JTI and ExpiresAt; ValidateToken returns all of them.
+type TokenClaims struct {
+	UserID        primitive.ObjectID
+	WorkspaceID   primitive.ObjectID
+	SessionID     primitive.ObjectID
+	Role          string
+	EmailVerified bool
+	JTI           string
+	ExpiresAt     time.Time
+}
+
+func CreateToken(tc TokenClaims, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
 	}
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"sub": userID.Hex(),
-		"exp": time.Now().Add(time.Duration(ttlHours) * time.Hour).Unix(),
-		"iat": time.Now().Unix(),
+		"typ":  "access",
+		"aud":  Audience(),
+		"sub":  tc.UserID.Hex(),
+		"wid":  tc.WorkspaceID.Hex(),
+		"sid":  tc.SessionID.Hex(),
+		"role": tc.Role,
+		"ev":   tc.EmailVerified,
+		"jti":  primitive.NewObjectID().Hex(),
+		"exp":  now.Add(ttl).Unix(),
+		"iat":  now.Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return signToken(claims)
 }
 
-func ValidateToken(tokenStr string) (primitive.ObjectID, error) {
-	if tokenStr == "" {
-		return primitive.NilObjectID, errors.New("empty token")
+func ValidateToken(tokenStr string) (*TokenClaims, error) {
+	claims, err := parseToken(tokenStr, "access", jwt.WithAudience(Audience()))
+	if err != nil {
+		return nil, err
 	}
-	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		// ensure signing method
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return jwtSecret, nil
-	})
-	if err != nil || !token.Valid {
-		return primitive.NilObjectID, errors.New("invalid token")
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return nil, errors.New("missing sub claim")
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
+	id, err := primitive.ObjectIDFromHex(sub)
+	if err != nil {
+		return nil, err
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, errors.New("missing jti claim")
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, errors.New("missing exp claim")
+	}
+	wid, ok := claims["wid"].(string)
 	if !ok {
-		return primitive.NilObjectID, errors.New("invalid token claims")
+		return nil, errors.New("missing wid claim")
+	}
+	workspaceID, err := primitive.ObjectIDFromHex(wid)
+	if err != nil {
+		return nil, err
+	}
+	sid, _ := claims["sid"].(string)
+	sessionID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		return nil, errors.New("missing sid claim")
+	}
+	role, _ := claims["role"].(string)
+	emailVerified, _ := claims["ev"].(bool)
+	return &TokenClaims{
+		UserID:        id,
+		WorkspaceID:   workspaceID,
+		SessionID:     sessionID,
+		Role:          role,
+		EmailVerified: emailVerified,
+		JTI:           jti,
+		ExpiresAt:     exp.Time,
+	}, nil
+}
+
+// CreateChallengeToken issues the short-lived token Login hands out when a
+// second factor is still owed. It can only be redeemed by VerifyTwoFactor.
+func CreateChallengeToken(userID primitive.ObjectID, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"typ": "2fa",
+		"sub": userID.Hex(),
+		"exp": now.Add(ttl).Unix(),
+		"iat": now.Unix(),
+	}
+	return signInternalToken(claims)
+}
+
+func ValidateChallengeToken(tokenStr string) (primitive.ObjectID, error) {
+	claims, err := parseInternalToken(tokenStr, "2fa")
+	if err != nil {
+		return primitive.NilObjectID, err
 	}
 	sub, ok := claims["sub"].(string)
 	if !ok {
 		return primitive.NilObjectID, errors.New("missing sub claim")
 	}
+	return primitive.ObjectIDFromHex(sub)
+}
+
+// CreateEmailToken signs a link token proving control of email. Binding the
+// address means a token goes stale if the user's email changes meanwhile.
+func CreateEmailToken(userID primitive.ObjectID, email string, ttl time.Duration) (string, error) {
+	return createEmailToken("email", userID, email, ttl)
+}
+
+func ValidateEmailToken(tokenStr string) (primitive.ObjectID, string, error) {
+	return validateEmailToken(tokenStr, "email")
+}
+
+func createEmailToken(typ string, userID primitive.ObjectID, email string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"typ":   typ,
+		"sub":   userID.Hex(),
+		"email": email,
+		"exp":   now.Add(ttl).Unix(),
+		"iat":   now.Unix(),
+	}
+	return signInternalToken(claims)
+}
+
+func validateEmailToken(tokenStr, typ string) (primitive.ObjectID, string, error) {
+	claims, err := parseInternalToken(tokenStr, typ)
+	if err != nil {
+		return primitive.NilObjectID, "", err
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return primitive.NilObjectID, "", errors.New("missing sub claim")
+	}
+	email, ok := claims["email"].(string)
+	if !ok || email == "" {
+		return primitive.NilObjectID, "", errors.New("missing email claim")
+	}
 	id, err := primitive.ObjectIDFromHex(sub)
 	if err != nil {
-		return primitive.NilObjectID, err
+		return primitive.NilObjectID, "", err
+	}
+	return id, email, nil
+}
+
+// signToken signs claims with the active key and stamps its kid and iss.
+// Only access tokens are signed this way, since the key is published.
+func signToken(claims jwt.MapClaims) (string, error) {
+	active := jwtKeys().active
+	claims["iss"] = Issuer()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = active.kid
+	return token.SignedString(active.priv)
+}
+
+// signInternalToken signs a token only pai-finance redeems, such as a 2FA
+// challenge or an email link, with the unpublished internal key so nothing
+// trusting the JWKS can mistake it for an access token.
+func signInternalToken(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = Issuer()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtKeys().internal)
+}
+
+// parseToken verifies an access token's signature, expiry and any extra
+// options, and checks the typ claim so a token minted for one purpose can't
+// be replayed for another.
+func parseToken(tokenStr, typ string, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
+	return parseWith(tokenStr, typ, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := jwtKeys().byKID[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.pub, nil
+	}, append(opts, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))...)
+}
+
+// parseInternalToken is parseToken for tokens from signInternalToken.
+func parseInternalToken(tokenStr, typ string) (jwt.MapClaims, error) {
+	return parseWith(tokenStr, typ, func(*jwt.Token) (interface{}, error) {
+		return jwtKeys().internal, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+}
+
+func parseWith(tokenStr, typ string, keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
+	if tokenStr == "" {
+		return nil, errors.New("empty token")
+	}
+	token, err := jwt.Parse(tokenStr, keyFunc, append(opts, jwt.WithIssuer(Issuer()))...)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	if t, _ := claims["typ"].(string); t != typ {
+		return nil, errors.New("unexpected token type")
 	}
-	return id, nil
+	return claims, nil
 }
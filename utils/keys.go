@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"log"
+	"os"
+)
+
+// signingKey is an Ed25519 key identified by its RFC 7638 thumbprint.
+// Retired keys only carry a public half and are kept for verification.
+type signingKey struct {
+	kid  string
+	priv ed25519.PrivateKey
+	pub  ed25519.PublicKey
+}
+
+type keySet struct {
+	active *signingKey
+	byKID  map[string]*signingKey
+	// internal is the HMAC key for tokens only pai-finance redeems. It is
+	// derived from the signing key, so it survives restarts and rotates with
+	// it, but is never published.
+	internal []byte
+}
+
+var keys *keySet
+
+// LoadJWTKeys loads the signing keys. It runs once at startup, after .env
+// has been read, so a bad key stops the server before it serves requests.
+//
+//	JWT_SIGNING_KEY        PEM PKCS#8 Ed25519 private key used to sign
+//	                       (openssl genpkey -algorithm ed25519)
+//	JWT_VERIFICATION_KEYS  zero or more PEM public keys of retired signing
+//	                       keys, still accepted until their tokens expire
+//
+// To rotate: move the old key's public half into JWT_VERIFICATION_KEYS, set
+// a new JWT_SIGNING_KEY, and drop the old public key once the longest-lived
+// token it signed has expired.
+func LoadJWTKeys() error {
+	ks, err := loadKeySet(os.Getenv("JWT_SIGNING_KEY"), os.Getenv("JWT_VERIFICATION_KEYS"))
+	if err != nil {
+		return err
+	}
+	keys = ks
+	return nil
+}
+
+func jwtKeys() *keySet {
+	if keys == nil {
+		panic("utils: JWT keys used before LoadJWTKeys")
+	}
+	return keys
+}
+
+func loadKeySet(signingPEM, verificationPEM string) (*keySet, error) {
+	ks := &keySet{byKID: map[string]*signingKey{}}
+
+	if signingPEM == "" {
+		// Fly sets FLY_APP_NAME; never fall back to a throwaway key there.
+		if os.Getenv("FLY_APP_NAME") != "" {
+			return nil, errors.New("JWT_SIGNING_KEY must be set")
+		}
+		log.Println("⚠️  JWT_SIGNING_KEY not set, using an ephemeral key; tokens won't survive a restart")
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		ks.active = newSigningKey(priv, pub)
+	} else {
+		block, _ := pem.Decode([]byte(signingPEM))
+		if block == nil {
+			return nil, errors.New("JWT_SIGNING_KEY is not PEM")
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("JWT_SIGNING_KEY is not an Ed25519 key")
+		}
+		ks.active = newSigningKey(priv, priv.Public().(ed25519.PublicKey))
+	}
+	ks.byKID[ks.active.kid] = ks.active
+	ks.internal = internalKey(ks.active.priv)
+
+	rest := []byte(verificationPEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("JWT_VERIFICATION_KEYS contains a non-Ed25519 key")
+		}
+		k := newSigningKey(nil, pub)
+		ks.byKID[k.kid] = k
+	}
+
+	return ks, nil
+}
+
+// internalKey derives the internal token key from an Ed25519 private key.
+func internalKey(priv ed25519.PrivateKey) []byte {
+	mac := hmac.New(sha256.New, priv.Seed())
+	mac.Write([]byte("pai-finance internal tokens"))
+	return mac.Sum(nil)
+}
+
+func newSigningKey(priv ed25519.PrivateKey, pub ed25519.PublicKey) *signingKey {
+	return &signingKey{kid: okpThumbprint(pub), priv: priv, pub: pub}
+}
+
+// okpThumbprint is the RFC 7638 JWK thumbprint of an Ed25519 public key.
+func okpThumbprint(pub ed25519.PublicKey) string {
+	// Members in lexicographic order, no whitespace, as the RFC requires.
+	canonical := `{"crv":"Ed25519","kty":"OKP","x":"` + base64.RawURLEncoding.EncodeToString(pub) + `"}`
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+}
+
+// JWKS returns every key a token may currently be verified with.
+func JWKS() []JWK {
+	ks := jwtKeys()
+	ordered := []*signingKey{ks.active}
+	for kid, k := range ks.byKID {
+		if kid != ks.active.kid {
+			ordered = append(ordered, k)
+		}
+	}
+	out := make([]JWK, 0, len(ordered))
+	for _, k := range ordered {
+		out = append(out, JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(k.pub),
+			Kid: k.kid,
+			Use: "sig",
+			Alg: "EdDSA",
+		})
+	}
+	return out
+}
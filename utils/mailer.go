@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Mail is a plain-text message.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional email (password resets, verification links).
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// SMTPMailer sends mail through an SMTP relay using PLAIN auth.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (sm *SMTPMailer) Send(m Mail) error {
+	var auth smtp.Auth
+	if sm.Username != "" {
+		auth = smtp.PlainAuth("", sm.Username, sm.Password, sm.Host)
+	}
+	sender, err := mail.ParseAddress(sm.From)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(sm.Host+":"+sm.Port, auth, sender.Address, []string{headerSafe.Replace(m.To)}, formatMail(sm.From, m))
+}
+
+// OutboxMailer writes each message to Dir as an .eml file instead of sending
+// it. Used for local development and tests.
+type OutboxMailer struct {
+	Dir  string
+	From string
+}
+
+func (om *OutboxMailer) Send(m Mail) error {
+	if err := os.MkdirAll(om.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), primitive.NewObjectID().Hex())
+	return os.WriteFile(filepath.Join(om.Dir, name), formatMail(om.From, m), 0o644)
+}
+
+// NewMailerFromEnv returns an SMTPMailer when SMTP_HOST is set and an
+// OutboxMailer writing to MAIL_OUTBOX_DIR otherwise. In production, where
+// nobody reads the outbox, a missing SMTP_HOST stops the server instead.
+func NewMailerFromEnv() Mailer {
+	from := getenv("MAIL_FROM", "Pai Finance <no-reply@pai-finance.local>")
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		return &SMTPMailer{
+			Host:     host,
+			Port:     getenv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	}
+	// Fly sets FLY_APP_NAME; never quietly drop reset links there.
+	if os.Getenv("FLY_APP_NAME") != "" {
+		log.Fatal("❌ SMTP_HOST must be set in production")
+	}
+	log.Println("⚠️  SMTP_HOST not set, writing mail to the outbox directory")
+	return &OutboxMailer{Dir: getenv("MAIL_OUTBOX_DIR", "outbox"), From: from}
+}
+
+// AppURL is the base URL of the frontend, used to build links in emails.
+func AppURL() string {
+	return strings.TrimRight(getenv("APP_URL", "http://localhost:5173"), "/")
+}
+
+// headerSafe strips line breaks so user-supplied values can't inject headers.
+var headerSafe = strings.NewReplacer("\r", "", "\n", "")
+
+func formatMail(from string, m Mail) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerSafe.Replace(from) + "\r\n")
+	b.WriteString("To: " + headerSafe.Replace(m.To) + "\r\n")
+	b.WriteString("Subject: " + headerSafe.Replace(m.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
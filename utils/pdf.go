@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PDFColumn is a column of a PDFReport table. Width is in millimetres and
+// Align is fpdf's "L", "C" or "R".
+type PDFColumn struct {
+	Title string
+	Width float64
+	Align string
+}
+
+// PDFReport lays out a landscape A4 report: a title and period header on
+// every page, a table whose column headings repeat after each page break,
+// and page numbers in the footer.
+type PDFReport struct {
+	pdf     *fpdf.Fpdf
+	tr      func(string) string
+	columns []PDFColumn
+	row     int
+}
+
+func NewPDFReport(title, period string, columns []PDFColumn) *PDFReport {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	r := &PDFReport{pdf: pdf, columns: columns}
+	// The core fonts are cp1252, which has no rupee sign.
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	r.tr = func(s string) string { return translate(strings.ReplaceAll(s, "₹", "Rs. ")) }
+
+	generated := "Generated " + time.Now().In(BusinessLocation()).Format("02 Jan 2006 15:04 MST")
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 7, r.tr(title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(0, 5, r.tr(period), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, generated, "", 1, "R", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(3)
+		r.headings()
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+	return r
+}
+
+func (r *PDFReport) headings() {
+	if len(r.columns) == 0 {
+		return
+	}
+	r.pdf.SetFont("Helvetica", "B", 9)
+	r.pdf.SetFillColor(230, 230, 230)
+	for _, c := range r.columns {
+		r.pdf.CellFormat(c.Width, 7, r.tr(c.Title), "1", 0, c.Align, true, 0, "")
+	}
+	r.pdf.Ln(-1)
+	r.pdf.SetFont("Helvetica", "", 9)
+}
+
+// Row adds a table row, one cell per column. Text that doesn't fit is cut.
+func (r *PDFReport) Row(cells ...string) {
+	r.row++
+	r.pdf.SetFillColor(247, 247, 247)
+	for i, c := range r.columns {
+		text := ""
+		if i < len(cells) {
+			text = r.fit(r.tr(cells[i]), c.Width-2)
+		}
+		r.pdf.CellFormat(c.Width, 6, text, "1", 0, c.Align, r.row%2 == 0, 0, "")
+	}
+	r.pdf.Ln(-1)
+}
+
+// Totals adds a bold row, for column totals under the table.
+func (r *PDFReport) Totals(cells ...string) {
+	r.pdf.SetFont("Helvetica", "B", 9)
+	for i, c := range r.columns {
+		text := ""
+		if i < len(cells) {
+			text = r.tr(cells[i])
+		}
+		r.pdf.CellFormat(c.Width, 7, text, "1", 0, c.Align, false, 0, "")
+	}
+	r.pdf.Ln(-1)
+	r.pdf.SetFont("Helvetica", "", 9)
+}
+
+// Summary starts a new page listing label and value pairs under heading.
+// The table's column headings no longer repeat after it.
+func (r *PDFReport) Summary(heading string, pairs [][2]string) {
+	r.columns = nil
+	r.pdf.AddPage()
+	r.pdf.SetFont("Helvetica", "B", 12)
+	r.pdf.CellFormat(0, 8, r.tr(heading), "", 1, "L", false, 0, "")
+	for _, p := range pairs {
+		r.pdf.SetFont("Helvetica", "", 10)
+		r.pdf.CellFormat(70, 7, r.tr(p[0]), "B", 0, "L", false, 0, "")
+		r.pdf.SetFont("Helvetica", "B", 10)
+		r.pdf.CellFormat(50, 7, r.tr(p[1]), "B", 1, "R", false, 0, "")
+	}
+}
+
+// Write renders the report to w.
+func (r *PDFReport) Write(w io.Writer) error {
+	return r.pdf.Output(w)
+}
+
+func (r *PDFReport) fit(s string, width float64) string {
+	if r.pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && r.pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrUnsupportedSpreadsheet is returned for files that are neither CSV nor
+// XLSX.
+var ErrUnsupportedSpreadsheet = errors.New("file must be .csv or .xlsx")
+
+// ReadSpreadsheet returns the rows of a CSV or XLSX file, picked by the
+// file name's extension. For XLSX, sheet defaults to the first sheet. XLSX
+// cells come back unformatted, so dates are Excel serial numbers; see
+// ExcelSerialDay.
+func ReadSpreadsheet(r io.Reader, filename, sheet string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if sheet == "" {
+			sheet = f.GetSheetName(0)
+		}
+		return f.GetRows(sheet, excelize.Options{RawCellValue: true})
+	default:
+		return nil, ErrUnsupportedSpreadsheet
+	}
+}
+
+// ExcelSerialDay converts an Excel date serial number to its calendar day,
+// stored as midnight UTC like every other day.
+func ExcelSerialDay(serial float64) (time.Time, error) {
+	t, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return CalendarDay(t), nil
+}